@@ -0,0 +1,104 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrAborted is the default reason of an AbortController aborted without
+// one.
+var ErrAborted = errors.New("aborted")
+
+// AbortController aborts every promise bound to its signal with a single
+// call, like the web API of the same name.
+type AbortController struct {
+	signal *AbortSignal
+}
+
+// AbortSignal tells executors whether the controller that owns it has
+// aborted. One signal may be shared by any number of promises.
+type AbortSignal struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	mutex  sync.Mutex
+	reason error
+}
+
+// NewAbortController returns a controller that has not aborted yet.
+func NewAbortController() *AbortController {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &AbortController{signal: &AbortSignal{ctx: ctx, cancel: cancel}}
+}
+
+// Signal returns the signal owned by c.
+func (c *AbortController) Signal() *AbortSignal {
+	return c.signal
+}
+
+// Abort aborts the signal of c with reason, or ErrAborted when reason is
+// nil. Every pending promise bound to the signal is cancelled with the
+// reason. Only the first call has any effect.
+func (c *AbortController) Abort(reason error) {
+	if reason == nil {
+		reason = ErrAborted
+	}
+	s := c.signal
+	s.mutex.Lock()
+	if s.reason == nil {
+		s.reason = reason
+	}
+	s.mutex.Unlock()
+	s.cancel()
+}
+
+// Aborted reports whether the signal has been aborted.
+func (s *AbortSignal) Aborted() bool {
+	return s.Reason() != nil
+}
+
+// Reason returns the reason the signal was aborted with, or nil.
+func (s *AbortSignal) Reason() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.reason
+}
+
+// Done returns a channel closed when the signal is aborted.
+func (s *AbortSignal) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Context returns a context cancelled when the signal is aborted, for passing
+// the signal on to context-aware APIs.
+func (s *AbortSignal) Context() context.Context {
+	return s.ctx
+}
+
+// NewWithSignal is like New but binds the promise to signal: the executor
+// receives the signal so it can stop its work, and the promise is cancelled
+// with the signal's reason as soon as the signal is aborted.
+func NewWithSignal[T any](signal *AbortSignal, exec func(signal *AbortSignal, resolve func(T), reject func(error))) *Promise[T] {
+	if signal == nil || exec == nil {
+		panic("signal and executor cannot be nil")
+	}
+	o := buildOptions(nil)
+	p := newPromise[T]()
+	p.start(func(resolve func(T), reject func(error)) {
+		exec(signal, resolve, reject)
+		// An executor that returns because of the abort must not race the
+		// cancellation below.
+		if reason := signal.Reason(); reason != nil {
+			p.Cancel(reason)
+		}
+	}, o)
+	p.enforce(o)
+	go func() {
+		select {
+		case <-signal.Done():
+			p.Cancel(signal.Reason())
+		case <-p.done:
+		}
+	}()
+	return p
+}
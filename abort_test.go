@@ -0,0 +1,48 @@
+package gopromise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAbortController(t *testing.T) {
+	controller := NewAbortController()
+	signal := controller.Signal()
+
+	fetch := func() *Promise[string] {
+		return NewWithSignal(signal, func(signal *AbortSignal, resolve func(string), reject func(error)) {
+			select {
+			case <-signal.Done():
+			case <-time.After(time.Second):
+				resolve("fetched")
+			}
+		})
+	}
+	p1, p2 := fetch(), fetch()
+	assert(t, !signal.Aborted())
+
+	controller.Abort(promiseError)
+	controller.Abort(nil)
+
+	for _, p := range []*Promise[string]{p1, p2} {
+		_, err := p.Await()
+		assert(t, errors.Is(err, ErrCancelled))
+		assert(t, errors.Is(err, promiseError))
+	}
+	assert(t, signal.Aborted())
+	assertEqual(t, promiseError, signal.Reason())
+	assertNotNil(t, signal.Context().Err())
+}
+
+func TestAbortController_DefaultReason(t *testing.T) {
+	controller := NewAbortController()
+	controller.Abort(nil)
+
+	p := NewWithSignal(controller.Signal(), func(signal *AbortSignal, resolve func(int), reject func(error)) {
+		<-signal.Done()
+	})
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrAborted))
+}
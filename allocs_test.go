@@ -0,0 +1,42 @@
+//go:build !race
+
+package gopromise
+
+import "testing"
+
+// TestAllocs guards the allocation counts of the hottest paths against
+// regressions. The race detector allocates on its own, hence the build tag.
+func TestAllocs(t *testing.T) {
+	recycler := NewRecycler[int]()
+	cases := []struct {
+		name string
+		max  float64
+		fn   func()
+	}{
+		{"Resolve", 1, func() { Resolve(1).Await() }},
+		// The promise, its done channel, the resolve and reject functions
+		// and the closure the goroutine runs.
+		{"New", 5, func() {
+			New(func(resolve func(int), reject func(error)) { resolve(1) }).Await()
+		}},
+		{"NewInline", 6, func() {
+			New(func(resolve func(int), reject func(error)) { resolve(1) }, WithInline()).Await()
+		}},
+		{"Deferred", 4, func() {
+			p, resolve, _ := Deferred[int]()
+			resolve(1)
+			p.Await()
+		}},
+		{"Recycler", 1, func() {
+			p, resolve, _ := recycler.Deferred()
+			resolve(1)
+			p.Await()
+			recycler.Release(p)
+		}},
+	}
+	for _, c := range cases {
+		if allocs := testing.AllocsPerRun(100, c.fn); allocs > c.max {
+			t.Errorf("%s: %v allocs per run, want at most %v", c.name, allocs, c.max)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gopromise
+
+import "testing"
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(func(resolve func(int), reject func(error)) { resolve(i) }).Await()
+	}
+}
+
+func BenchmarkDeferred(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p, resolve, _ := Deferred[int]()
+		resolve(i)
+		p.Await()
+	}
+}
+
+func BenchmarkResolve(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Resolve(i).Await()
+	}
+}
+
+func BenchmarkTryAwait_Parallel(b *testing.B) {
+	p := Resolve(1)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.TryAwait()
+		}
+	})
+}
+
+func BenchmarkRecycler(b *testing.B) {
+	r := NewRecycler[int]()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p, resolve, _ := r.Deferred()
+		resolve(i)
+		p.Await()
+		r.Release(p)
+	}
+}
+
+func BenchmarkNew_Inline(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(func(resolve func(int), reject func(error)) { resolve(i) }, WithInline()).Await()
+	}
+}
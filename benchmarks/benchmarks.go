@@ -0,0 +1,160 @@
+// Package benchmarks holds representative gopromise workloads as benchmark
+// helpers, so that schedulers can be compared with each other and the library
+// with hand-rolled channels. A downstream module runs them from its own
+// benchmarks:
+//
+//	func BenchmarkMyScheduler(b *testing.B) {
+//		benchmarks.Run(b, myScheduler)
+//	}
+//
+// Each helper runs promise executors on the given Scheduler, and installs it
+// as the default Scheduler for the continuations while it runs. A nil
+// Scheduler runs each executor on a goroutine of its own.
+package benchmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/migzzi/gopromise"
+)
+
+// Scenario is a named workload with the parameters used by Run.
+type Scenario struct {
+	Name string
+	Run  func(b *testing.B, s gopromise.Scheduler)
+}
+
+// Scenarios are the workloads run by Run.
+var Scenarios = []Scenario{
+	{"DeepChain/1000", func(b *testing.B, s gopromise.Scheduler) { DeepChain(b, 1000, s) }},
+	{"WideAll/1000", func(b *testing.B, s gopromise.Scheduler) { WideAll(b, 1000, s) }},
+	{"RaceSlowLosers/100", func(b *testing.B, s gopromise.Scheduler) { RaceSlowLosers(b, 100, time.Second, s) }},
+	{"Map/100000", func(b *testing.B, s gopromise.Scheduler) { Map(b, 100000, s) }},
+}
+
+// Run runs every Scenario on s as a sub-benchmark of b.
+func Run(b *testing.B, s gopromise.Scheduler) {
+	for _, sc := range Scenarios {
+		sc := sc
+		b.Run(sc.Name, func(b *testing.B) { sc.Run(b, s) })
+	}
+}
+
+// DeepChain measures a promise followed by depth Then steps.
+func DeepChain(b *testing.B, depth int, s gopromise.Scheduler) {
+	defer useScheduler(s)()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := gopromise.New(func(resolve func(int), reject func(error)) { resolve(0) }, gopromise.WithScheduler(s))
+		for d := 0; d < depth; d++ {
+			p = p.Then(func(val int) int { return val + 1 })
+		}
+		if val, err := p.Await(); err != nil || val != depth {
+			b.Fatalf("got %d, %v; want %d", val, err, depth)
+		}
+	}
+}
+
+// WideAll measures All over width promises that fulfill right away.
+func WideAll(b *testing.B, width int, s gopromise.Scheduler) {
+	defer useScheduler(s)()
+	b.ReportAllocs()
+	promises := make([]*gopromise.Promise[int], width)
+	for i := 0; i < b.N; i++ {
+		for idx := range promises {
+			idx := idx
+			promises[idx] = gopromise.New(func(resolve func(int), reject func(error)) { resolve(idx) }, gopromise.WithScheduler(s))
+		}
+		if _, err := gopromise.All(promises...).Await(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// RaceSlowLosers measures Race between a promise that fulfills right away
+// and losers promises that would only settle after delay.
+func RaceSlowLosers(b *testing.B, losers int, delay time.Duration, s gopromise.Scheduler) {
+	defer useScheduler(s)()
+	b.ReportAllocs()
+	promises := make([]*gopromise.Promise[int], losers+1)
+	timers := make([]*time.Timer, losers)
+	for i := 0; i < b.N; i++ {
+		for idx := 0; idx < losers; idx++ {
+			p, resolve, _ := gopromise.Deferred[int]()
+			timers[idx] = time.AfterFunc(delay, func() { resolve(-1) })
+			promises[idx] = p
+		}
+		promises[losers] = gopromise.New(func(resolve func(int), reject func(error)) { resolve(1) }, gopromise.WithScheduler(s))
+		if val, err := gopromise.Race(promises...).Await(); err != nil || val != 1 {
+			b.Fatalf("got %d, %v; want 1", val, err)
+		}
+		for _, timer := range timers {
+			timer.Stop()
+		}
+	}
+}
+
+// Map measures Map over n items, each mapped by an executor that doubles it.
+func Map(b *testing.B, n int, s gopromise.Scheduler) {
+	defer useScheduler(s)()
+	b.ReportAllocs()
+	items := make([]int, n)
+	for idx := range items {
+		items[idx] = idx
+	}
+	double := func(item int) *gopromise.Promise[int] {
+		return gopromise.New(func(resolve func(int), reject func(error)) { resolve(item * 2) }, gopromise.WithScheduler(s))
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := gopromise.Map(items, double).Await(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// DeepChainChannels is the hand-rolled counterpart of DeepChain: a goroutine
+// per step, each passing the value on through a channel.
+func DeepChainChannels(b *testing.B, depth int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		in := make(chan int, 1)
+		in <- 0
+		ch := in
+		for d := 0; d < depth; d++ {
+			next := make(chan int, 1)
+			go func(in <-chan int) { next <- <-in + 1 }(ch)
+			ch = next
+		}
+		if val := <-ch; val != depth {
+			b.Fatalf("got %d; want %d", val, depth)
+		}
+	}
+}
+
+// WideAllChannels is the hand-rolled counterpart of WideAll: width
+// goroutines sending their index on a shared channel.
+func WideAllChannels(b *testing.B, width int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ch := make(chan int, width)
+		for idx := 0; idx < width; idx++ {
+			go func(idx int) { ch <- idx }(idx)
+		}
+		values := make([]int, width)
+		for n := 0; n < width; n++ {
+			idx := <-ch
+			values[idx] = idx
+		}
+	}
+}
+
+// useScheduler makes s the default Scheduler and returns a function that
+// restores the previous defaults.
+func useScheduler(s gopromise.Scheduler) func() {
+	prev := gopromise.Defaults()
+	c := prev
+	c.Scheduler = s
+	gopromise.SetDefaults(c)
+	return func() { gopromise.SetDefaults(prev) }
+}
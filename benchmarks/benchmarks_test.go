@@ -0,0 +1,22 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"github.com/migzzi/gopromise"
+)
+
+func BenchmarkGoroutines(b *testing.B) {
+	Run(b, nil)
+}
+
+func BenchmarkPoolScheduler(b *testing.B) {
+	s := gopromise.PoolScheduler(8, 1024, gopromise.RunWhenFull)
+	defer s.Close()
+	Run(b, s)
+}
+
+func BenchmarkChannels(b *testing.B) {
+	b.Run("DeepChain/1000", func(b *testing.B) { DeepChainChannels(b, 1000) })
+	b.Run("WideAll/1000", func(b *testing.B) { WideAllChannels(b, 1000) })
+}
@@ -0,0 +1,68 @@
+package gopromise
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrBulkheadFull is the rejection reason of calls refused by a Bulkhead
+// because both its running and its queued calls were at their limit.
+var ErrBulkheadFull = errors.New("bulkhead full")
+
+// Bulkhead isolates a dependency by capping how many calls to it may run,
+// and how many more may wait, at once. Calls beyond both limits are refused
+// right away instead of piling up.
+type Bulkhead struct {
+	sem       *Semaphore
+	maxQueued int64
+	queued    atomic.Int64
+}
+
+// NewBulkhead returns a Bulkhead that runs up to maxConcurrent calls at a
+// time and queues up to maxQueued more.
+func NewBulkhead(maxConcurrent, maxQueued int) *Bulkhead {
+	if maxConcurrent <= 0 {
+		panic("maxConcurrent must be positive")
+	}
+	if maxQueued < 0 {
+		maxQueued = 0
+	}
+	return &Bulkhead{sem: NewSemaphore(int64(maxConcurrent)), maxQueued: int64(maxQueued)}
+}
+
+// Bulkheaded wraps factory so that its calls go through b. A call refused by
+// b rejects with ErrBulkheadFull without calling factory; a queued call
+// calls factory once a running one settles, or never if its promise is
+// cancelled first.
+func Bulkheaded[T any](b *Bulkhead, factory func() *Promise[T]) func() *Promise[T] {
+	if b == nil || factory == nil {
+		panic("bulkhead and factory cannot be nil")
+	}
+	return func() *Promise[T] {
+		admitted := b.sem.TryAcquire(1)
+		if !admitted {
+			if b.queued.Add(1) > b.maxQueued {
+				b.queued.Add(-1)
+				return Reject[T](ErrBulkheadFull)
+			}
+		}
+		p := newPromise[T]()
+		p.start(func(resolve func(T), reject func(error)) {
+			if !admitted {
+				ok := b.sem.acquire(1, p.done)
+				b.queued.Add(-1)
+				if !ok {
+					return
+				}
+			}
+			defer b.sem.Release(1)
+			val, err := orReject(factory()).await()
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(val)
+		}, nil)
+		return p
+	}
+}
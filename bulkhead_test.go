@@ -0,0 +1,44 @@
+package gopromise
+
+import (
+	"testing"
+)
+
+func TestBulkhead(t *testing.T) {
+	b := NewBulkhead(1, 1)
+	gate := make(chan struct{})
+	calls := 0
+	call := Bulkheaded(b, func() *Promise[int] {
+		calls++
+		n := calls
+		return New(func(resolve func(int), reject func(error)) {
+			<-gate
+			resolve(n)
+		})
+	})
+
+	running := call()
+	queued := call()
+	_, err := call().Await()
+	assertEqual(t, ErrBulkheadFull, err)
+
+	close(gate)
+	res, err := running.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+	res, err = queued.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+
+	res, err = call().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, res, "the bulkhead should admit calls again once they settle")
+}
+
+func TestBulkhead_NilPromise(t *testing.T) {
+	call := Bulkheaded(NewBulkhead(1, 0), func() *Promise[int] { return nil })
+	_, err := call().Await()
+	assertEqual(t, ErrNilPromise, err)
+	_, err = call().Await()
+	assertEqual(t, ErrNilPromise, err)
+}
@@ -0,0 +1,47 @@
+package gopromise
+
+// OnComplete calls cb with the outcome of p once it settles. cb runs on its
+// own goroutine, so OnComplete never blocks. A nil p is treated as a promise
+// rejected with ErrNilPromise.
+func OnComplete[T any](p *Promise[T], cb func(val T, err error)) {
+	p = orReject(p)
+	go func() {
+		cb(p.await())
+	}()
+}
+
+// ToCallbackStyle adapts a promise-returning function to the callback style
+// of older APIs: the returned function starts fn and reports its outcome to
+// the callback it is given.
+func ToCallbackStyle[A, T any](fn func(arg A) *Promise[T]) func(arg A, cb func(val T, err error)) {
+	return func(arg A, cb func(val T, err error)) {
+		OnComplete(fn(arg), cb)
+	}
+}
+
+// FromCallback returns a promise settled by the first call of the callback
+// register is given; later calls are ignored. register is called right away,
+// on the calling goroutine, and is expected to hand the callback to an
+// asynchronous API.
+func FromCallback[T any](register func(cb func(val T, err error))) *Promise[T] {
+	p := newPromise[T]()
+	register(func(val T, err error) {
+		if err != nil {
+			p.reject(err)
+			return
+		}
+		p.resolve(val)
+	})
+	return p
+}
+
+// FromCallbackStyle is the inverse of ToCallbackStyle: it adapts a
+// callback-style function to return a promise settled by the first call of
+// its callback.
+func FromCallbackStyle[A, T any](fn func(arg A, cb func(val T, err error))) func(arg A) *Promise[T] {
+	return func(arg A) *Promise[T] {
+		return FromCallback(func(cb func(val T, err error)) {
+			fn(arg, cb)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package gopromise
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestOnComplete(t *testing.T) {
+	done := make(chan string, 1)
+	OnComplete(Resolve("done"), func(val string, err error) {
+		assertNil(t, err)
+		done <- val
+	})
+	assertEqual(t, "done", <-done)
+}
+
+func TestOnComplete_NilPromise(t *testing.T) {
+	done := make(chan error, 1)
+	OnComplete(nil, func(val string, err error) { done <- err })
+	assertEqual(t, ErrNilPromise, <-done)
+}
+
+func TestToCallbackStyle(t *testing.T) {
+	parse := ToCallbackStyle(func(s string) *Promise[int] {
+		return New(func(resolve func(int), reject func(error)) {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(n)
+		})
+	})
+
+	ok, bad := make(chan error, 1), make(chan error, 1)
+	parse("42", func(val int, err error) {
+		assertEqual(t, 42, val)
+		ok <- err
+	})
+	parse("nope", func(val int, err error) {
+		bad <- err
+	})
+	assertNil(t, <-ok)
+	assertErr(t, <-bad)
+}
+
+func TestFromCallbackStyle(t *testing.T) {
+	legacy := func(n int, cb func(int, error)) {
+		go func() {
+			if n < 0 {
+				cb(0, promiseError)
+				return
+			}
+			cb(n*n, nil)
+			cb(-1, nil)
+		}()
+	}
+	square := FromCallbackStyle(legacy)
+
+	res, err := square(4).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 16, res)
+
+	_, err = square(-1).Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestFromCallback(t *testing.T) {
+	var cb func(string, error)
+	p := FromCallback(func(fn func(string, error)) { cb = fn })
+	assert(t, p.IsPending(), "promise should wait for the callback")
+
+	go func() {
+		cb("first", nil)
+		cb("second", promiseError)
+	}()
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, "first", res)
+}
@@ -0,0 +1,321 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrRaceLost is the cause given to the inputs of Race that are cancelled
+// because another input settled first.
+var ErrRaceLost = errors.New("lost race")
+
+// NewWithContext is like New but hands the executor a context that is
+// cancelled when the promise is cancelled, so the executor can stop its work.
+// The context is also cancelled once the promise settles. It honours
+// WithTimeout and WithContext, whose context becomes the parent of the one
+// handed to the executor.
+func NewWithContext[T any](exec func(ctx context.Context, resolve func(T), reject func(error)), opts ...Option) *Promise[T] {
+	if exec == nil {
+		panic("executor cannot be nil")
+	}
+	o := buildOptions(opts)
+	parent := o.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	p := newNamedPromise[T](o.name)
+	p.cleanups = &cleanupList{}
+	ctx, cancel := context.WithCancel(context.WithValue(parent, cleanupKey{}, p.cleanups))
+	p.cancel = cancel
+	p.start(func(resolve func(T), reject func(error)) {
+		exec(ctx, resolve, reject)
+		// An executor that returns because its parent context is done must
+		// not race the cancellation by enforce.
+		if ctx.Err() != nil {
+			p.Cancel(context.Cause(ctx))
+		}
+	}, o)
+	p.enforce(o)
+	return p
+}
+
+type cleanupKey struct{}
+
+type cleanupState int
+
+const (
+	cleanupPending cleanupState = iota
+	cleanupFired
+	cleanupDiscarded
+)
+
+// cleanupList holds the functions registered through OnCancel.
+type cleanupList struct {
+	mutex sync.Mutex
+	fns   []func()
+	state cleanupState
+}
+
+// OnCancel registers cleanup to run if the promise whose executor received
+// ctx from NewWithContext is cancelled or times out, e.g. to close a socket
+// or delete a temporary file the executor opened. Cleanups run in reverse
+// order of registration, right after the promise settles; one registered
+// after that runs immediately. They never run when the promise fulfills or
+// rejects on its own.
+//
+// OnCancel reports false, without registering anything, when ctx does not
+// come from NewWithContext.
+func OnCancel(ctx context.Context, cleanup func()) bool {
+	c, ok := ctx.Value(cleanupKey{}).(*cleanupList)
+	if !ok {
+		return false
+	}
+	c.mutex.Lock()
+	switch c.state {
+	case cleanupPending:
+		c.fns = append(c.fns, cleanup)
+		c.mutex.Unlock()
+	case cleanupFired:
+		c.mutex.Unlock()
+		cleanup()
+	default:
+		c.mutex.Unlock()
+	}
+	return true
+}
+
+func (c *cleanupList) fire() {
+	c.mutex.Lock()
+	fns := c.fns
+	c.fns, c.state = nil, cleanupFired
+	c.mutex.Unlock()
+	for idx := len(fns) - 1; idx >= 0; idx-- {
+		fns[idx]()
+	}
+}
+
+func (c *cleanupList) discard() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fns, c.state = nil, cleanupDiscarded
+}
+
+// Cancel settles p as Cancelled, if it is still pending, and signals its
+// executor to stop through the context given by NewWithContext. Awaiting a
+// cancelled promise returns ErrCancelled, or an error wrapping both
+// ErrCancelled and cause when cause is not nil.
+//
+// Executors of promises created by New are not signalled; their work runs to
+// completion but its outcome is ignored.
+//
+// Cancelling a promise derived from others, e.g. by Then, Catch, All or
+// Race, also cancels each source promise that no other derived promise
+// depends on anymore, provided the source was itself derived or created by
+// NewWithContext. Cancellation thus travels up a chain as far as nobody else
+// needs the results.
+func (p *Promise[T]) Cancel(cause error) {
+	reason := ErrCancelled
+	if cause != nil {
+		reason = &cancelError{cause: cause}
+	}
+	var zero T
+	if p.settle(Cancelled, zero, reason, false) {
+		p.releaseUpstream(cause)
+	}
+}
+
+// releaseUpstream drops the claims of p on its sources, at most once.
+func (p *Promise[T]) releaseUpstream(cause error) {
+	if p.released.CompareAndSwap(false, true) {
+		for _, src := range p.upstream {
+			src.release(cause)
+		}
+	}
+}
+
+// source is the type-erased view of a promise that others derive from.
+type source interface {
+	acquire()
+	release(cause error)
+}
+
+// release drops the claim of one derived promise on p and cancels p with
+// cause when that was the last claim and p may be cancelled on behalf of its
+// consumers.
+func (p *Promise[T]) release(cause error) {
+	if p == nil {
+		return
+	}
+	if p.consumers.Add(-1) == 0 && (p.derived || p.cancel != nil) {
+		p.Cancel(cause)
+	}
+}
+
+// derive starts a library executor that computes a promise from the given
+// sources, registering the new promise as a consumer of each of them.
+func derive[T any](exec func(resolve func(T), reject func(error)), upstream ...source) *Promise[T] {
+	p := newDerived[T](upstream...)
+	p.start(exec, nil)
+	return p
+}
+
+// continuation is like derive for an executor that depends on src alone.
+// Rather than parking a goroutine until src settles, it registers exec to run
+// once src has settled: on the default scheduler when one is set, on a
+// goroutine shared with the other continuations of src, or on the calling
+// goroutine when src has already settled.
+func continuation[T, R any](src *Promise[T], exec func(resolve func(R), reject func(error))) *Promise[R] {
+	if src == nil {
+		return Reject[R](ErrNilPromise)
+	}
+	p := newDerived[R](src)
+	src.schedule(p.task(exec, nil))
+	return p
+}
+
+// schedule arranges for the continuation task to run once p has settled, on
+// the default Scheduler when there is one. Otherwise the task runs as
+// dispatch describes, or right away on the calling goroutine when p has
+// already settled.
+func (p *Promise[T]) schedule(task func()) {
+	p.wake()
+	p.mutex.Lock()
+	if p.State() == Pending {
+		p.continuations = append(p.continuations, task)
+		p.mutex.Unlock()
+		return
+	}
+	p.mutex.Unlock()
+	if s := Defaults().Scheduler; s == nil || s.Schedule(task) != nil {
+		task()
+	}
+}
+
+// dispatch runs the continuations of a promise that just settled. They are
+// handed to the default Scheduler in batches of Config.ContinuationBatch, and
+// run inline when it refuses a batch. With no Scheduler, they run one after
+// the other on a single new goroutine.
+func dispatch(tasks []func()) {
+	c := Defaults()
+	if c.Scheduler == nil {
+		go func() {
+			for _, task := range tasks {
+				task()
+			}
+		}()
+		return
+	}
+	size := c.ContinuationBatch
+	if size < 1 {
+		size = 1
+	}
+	for len(tasks) > 0 {
+		n := size
+		if n > len(tasks) {
+			n = len(tasks)
+		}
+		batch := tasks[:n]
+		tasks = tasks[n:]
+		run := batch[0]
+		if n > 1 {
+			run = func() {
+				for _, task := range batch {
+					task()
+				}
+			}
+		}
+		if c.Scheduler.Schedule(run) != nil {
+			run()
+		}
+	}
+}
+
+// newDerived returns a pending promise registered as a consumer of upstream,
+// for combinators whose executor needs a reference to the promise itself.
+func newDerived[T any](upstream ...source) *Promise[T] {
+	return newNamedDerived[T]("", upstream...)
+}
+
+// newNamedDerived is like newDerived but names the promise.
+func newNamedDerived[T any](name string, upstream ...source) *Promise[T] {
+	p := newNamedPromise[T](name)
+	p.derived = true
+	p.upstream = upstream
+	for _, src := range upstream {
+		src.acquire()
+	}
+	return p
+}
+
+// follow returns a promise that settles like src, so that src can be shared
+// among callers without one of them cancelling it for the others.
+func follow[T any](src *Promise[T]) *Promise[T] {
+	return derive(func(resolve func(T), reject func(error)) {
+		val, err := src.await()
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(val)
+	}, src)
+}
+
+func (p *Promise[T]) acquire() {
+	if p == nil {
+		return
+	}
+	p.consumers.Add(1)
+}
+
+// orRejected returns promises with each nil promise replaced by one rejected
+// with ErrNilPromise, so that combinators treat it as an input that failed.
+func orRejected[T any](promises []*Promise[T]) []*Promise[T] {
+	for idx, p := range promises {
+		if p != nil {
+			continue
+		}
+		replaced := make([]*Promise[T], len(promises))
+		copy(replaced, promises)
+		for ; idx < len(replaced); idx++ {
+			if replaced[idx] == nil {
+				replaced[idx] = Reject[T](ErrNilPromise)
+			}
+		}
+		return replaced
+	}
+	return promises
+}
+
+// orReject is orRejected for a single promise.
+func orReject[T any](p *Promise[T]) *Promise[T] {
+	if p == nil {
+		return Reject[T](ErrNilPromise)
+	}
+	return p
+}
+
+func sources[T any](promises []*Promise[T]) []source {
+	upstream := make([]source, len(promises))
+	for idx, p := range promises {
+		upstream[idx] = p
+	}
+	return upstream
+}
+
+// AsContext returns a context derived from parent that is cancelled once p
+// settles. When p rejects or is cancelled, context.Cause on the returned
+// context reports the rejection reason. The returned CancelFunc releases the
+// context early.
+func AsContext[T any](parent context.Context, p *Promise[T]) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	p.wake()
+	go func() {
+		select {
+		case <-p.done:
+			cancel(p.reason)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
@@ -0,0 +1,221 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPromise_Cancel(t *testing.T) {
+	stopped := make(chan struct{})
+	p := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	p.Cancel(nil)
+
+	_, err := p.Await()
+	assertEqual(t, ErrCancelled, err)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("executor was not signalled")
+	}
+}
+
+func TestPromise_CancelWithCause(t *testing.T) {
+	p := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(50 * time.Millisecond)
+		resolve(1)
+	})
+
+	p.Cancel(promiseError)
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+	assert(t, errors.Is(err, promiseError))
+
+	time.Sleep(80 * time.Millisecond)
+	_, err = p.Await()
+	assert(t, errors.Is(err, ErrCancelled), "late resolve should be ignored")
+}
+
+func TestPromise_CancelSettled(t *testing.T) {
+	p := Resolve(1)
+	p.Cancel(nil)
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+}
+
+func TestPromise_CancelPropagates(t *testing.T) {
+	stopped := make(chan struct{})
+	src := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	mid := Then(src, func(v int) int { return v + 1 })
+	tail := Then(mid, func(v int) int { return v * 2 })
+
+	tail.Cancel(promiseError)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("cancellation did not reach the source")
+	}
+	_, err := src.Await()
+	assert(t, errors.Is(err, promiseError))
+	_, err = mid.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+}
+
+func TestPromise_CancelKeepsSharedSource(t *testing.T) {
+	src := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		time.Sleep(20 * time.Millisecond)
+		resolve(1)
+	})
+	a := Then(src, func(v int) int { return v + 1 })
+	b := Then(src, func(v int) int { return v + 2 })
+
+	a.Cancel(nil)
+
+	res, err := b.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, res)
+	_, err = src.Await()
+	assertNotErr(t, err)
+}
+
+func TestAll_CancelPropagates(t *testing.T) {
+	srcs := make([]*Promise[int], 3)
+	for idx := range srcs {
+		srcs[idx] = NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+			<-ctx.Done()
+		})
+	}
+	p := All(srcs...)
+
+	p.Cancel(nil)
+
+	for _, src := range srcs {
+		_, err := src.Await()
+		assertEqual(t, ErrCancelled, err)
+	}
+}
+
+func TestRace_CancelsLosers(t *testing.T) {
+	stopped := make(chan struct{})
+	loser := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	plain := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(30 * time.Millisecond)
+		resolve(3)
+	})
+
+	res, err := Race(loser, Resolve(1), plain).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("losing promise was not cancelled")
+	}
+	_, err = loser.Await()
+	assert(t, errors.Is(err, ErrRaceLost))
+
+	res, err = plain.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, res)
+}
+
+func TestAll_CancelsRemainingOnRejection(t *testing.T) {
+	stopped := make(chan struct{})
+	slow := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	_, err := All(slow, Reject[int](promiseError)).Await()
+	assert(t, errors.Is(err, promiseError))
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("remaining promise was not cancelled")
+	}
+	_, err = slow.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+	assert(t, errors.Is(err, promiseError))
+}
+
+func TestAsContext(t *testing.T) {
+	guard := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(10 * time.Millisecond)
+		reject(promiseError)
+	})
+	ctx, cancel := AsContext(context.Background(), guard)
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not cancelled")
+	}
+	assertEqual(t, context.Canceled, ctx.Err())
+	assertEqual(t, promiseError, context.Cause(ctx))
+
+	ctx, cancel = AsContext(context.Background(), Resolve(1))
+	defer cancel()
+	<-ctx.Done()
+	assertEqual(t, context.Canceled, context.Cause(ctx))
+}
+
+func TestOnCancel(t *testing.T) {
+	var order []int
+	registered := make(chan struct{})
+	p := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		OnCancel(ctx, func() { order = append(order, 1) })
+		OnCancel(ctx, func() { order = append(order, 2) })
+		close(registered)
+		<-ctx.Done()
+	})
+	<-registered
+
+	p.Cancel(nil)
+
+	assertEqual(t, 2, len(order))
+	assertEqual(t, 2, order[0])
+	assertEqual(t, 1, order[1])
+}
+
+func TestOnCancel_Timeout(t *testing.T) {
+	cleaned := make(chan struct{})
+	p := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		OnCancel(ctx, func() { close(cleaned) })
+		<-ctx.Done()
+	}, WithTimeout(10*time.Millisecond))
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrTimeout), "expected a timeout")
+	<-cleaned
+}
+
+func TestOnCancel_NotOnFulfillment(t *testing.T) {
+	ran := false
+	p := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		OnCancel(ctx, func() { ran = true })
+		resolve(1)
+	})
+	p.Await()
+	p.Cancel(nil)
+	assert(t, !ran, "cleanup should not run after fulfillment")
+
+	assert(t, !OnCancel(context.Background(), func() {}))
+}
@@ -0,0 +1,453 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPollTimeout is wrapped, together with a *TimeoutError, by the
+	// rejection reason of UntilWithin when cond does not report done in time.
+	ErrPollTimeout = errors.New("poll timed out")
+	// ErrNoValue is the rejection reason of Coalesce when no promise
+	// fulfilled with a non-zero value.
+	ErrNoValue = errors.New("no promise fulfilled with a non-zero value")
+	// ErrNoPromises is the rejection reason of combinators that were given
+	// nothing to wait on.
+	ErrNoPromises = errors.New("no promises given")
+	// ErrQuorumUnreachable is wrapped by the rejection reason of Quorum once
+	// too many promises have rejected for the quorum to be met.
+	ErrQuorumUnreachable = errors.New("quorum unreachable")
+)
+
+// Until returns a Promise that calls cond right away and then once every
+// interval, fulfilling with the value cond returns once it reports done. The
+// promise rejects as soon as cond returns an error.
+func Until[T any](interval time.Duration, cond func() (T, bool, error)) *Promise[T] {
+	return UntilWithin(interval, 0, cond)
+}
+
+// UntilWithin is like Until but rejects with an error wrapping ErrPollTimeout
+// when cond has not reported done after max. A max of zero or less means no limit.
+// An interval of zero or less rejects right away, without calling cond.
+func UntilWithin[T any](interval, max time.Duration, cond func() (T, bool, error)) *Promise[T] {
+	if cond == nil {
+		panic("condition cannot be nil")
+	}
+	if interval <= 0 {
+		return Reject[T](fmt.Errorf("until: interval must be positive, got %v", interval))
+	}
+	return run(func(resolve func(T), reject func(error)) {
+		var deadline <-chan time.Time
+		if max > 0 {
+			timer := time.NewTimer(max)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			val, done, err := cond()
+			if err != nil {
+				reject(err)
+				return
+			}
+			if done {
+				resolve(val)
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-deadline:
+				reject(fmt.Errorf("%w: %w", ErrPollTimeout, &TimeoutError{After: max}))
+				return
+			}
+		}
+	})
+}
+
+// While returns a Promise that runs body for as long as cond holds, feeding
+// each iteration the value the previous one fulfilled with, starting from
+// seed. It fulfills with the first value for which cond is false and rejects
+// as soon as an iteration rejects, which a nil promise from body counts as,
+// with ErrNilPromise. All iterations run on a single goroutine, however many
+// there are.
+func While[T any](seed T, cond func(T) bool, body func(T) *Promise[T]) *Promise[T] {
+	if cond == nil || body == nil {
+		panic("condition and body cannot be nil")
+	}
+	return run(func(resolve func(T), reject func(error)) {
+		val := seed
+		for cond(val) {
+			next, err := orReject(body(val)).await()
+			if err != nil {
+				reject(err)
+				return
+			}
+			val = next
+		}
+		resolve(val)
+	})
+}
+
+// Coalesce returns a Promise that fulfills with the first non-zero value any
+// of promises fulfills with, skipping zero values and rejections. It rejects
+// with ErrNoValue once every promise has settled without such a value.
+func Coalesce[T comparable](promises ...*Promise[T]) *Promise[T] {
+	promises = orRejected(promises)
+	return derive(func(resolve func(T), reject func(error)) {
+		var zero T
+		valueChan := make(chan T, len(promises))
+		for _, p := range promises {
+			p := p
+			p.whenSettled(func() { valueChan <- p.value })
+		}
+
+		for idx := 0; idx < len(promises); idx++ {
+			if val := <-valueChan; val != zero {
+				resolve(val)
+				return
+			}
+		}
+		reject(ErrNoValue)
+	}, sources(promises)...)
+}
+
+// Hedge returns a Promise that starts the first factory and, each time delay
+// passes without a fulfillment, starts the next one, fulfilling with
+// whichever started promise fulfills first. A rejection starts the next
+// factory right away. Hedge rejects with the last rejection reason once every
+// factory has been started and rejected, or with ErrNoPromises when there
+// are no factories. A factory that returns nil counts as one that rejected
+// with ErrNilPromise.
+//
+// Once a promise fulfills, Hedge gives up its claim on the others, which
+// cancels those that Cancel would cancel upstream: promises that were derived
+// or created by NewWithContext and that nothing else depends on. Their cause
+// is ErrRaceLost.
+func Hedge[T any](delay time.Duration, factories ...func() *Promise[T]) *Promise[T] {
+	return run(func(resolve func(T), reject func(error)) {
+		if len(factories) == 0 {
+			reject(ErrNoPromises)
+			return
+		}
+
+		type result struct {
+			val T
+			err error
+		}
+		results := make(chan result, len(factories))
+		started := make([]*Promise[T], 0, len(factories))
+		launched, pending := 0, 0
+		launch := func() {
+			p := orReject(factories[launched]())
+			p.acquire()
+			started = append(started, p)
+			launched++
+			pending++
+			go func() {
+				val, err := p.await()
+				results <- result{val, err}
+			}()
+		}
+
+		launch()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		var lastErr error
+		for pending > 0 {
+			select {
+			case res := <-results:
+				pending--
+				if res.err == nil {
+					resolve(res.val)
+					for _, p := range started {
+						p.release(ErrRaceLost)
+					}
+					return
+				}
+				lastErr = res.err
+				if launched < len(factories) {
+					launch()
+					if !timer.Stop() {
+						select {
+						case <-timer.C:
+						default:
+						}
+					}
+					timer.Reset(delay)
+				}
+			case <-timer.C:
+				if launched < len(factories) {
+					launch()
+					timer.Reset(delay)
+				}
+			}
+		}
+		reject(lastErr)
+	})
+}
+
+// Quorum returns a Promise that fulfills with the values of the first k
+// promises to fulfill, in the order they fulfilled. It rejects with an error
+// wrapping ErrQuorumUnreachable as soon as len(promises)-k+1 promises have
+// rejected, since the quorum can no longer be met. The error also wraps an
+// *AggregateError holding the rejection reasons seen so far.
+func Quorum[T any](k int, promises ...*Promise[T]) *Promise[[]T] {
+	promises = orRejected(promises)
+	return derive(func(resolve func([]T), reject func(error)) {
+		if k <= 0 {
+			resolve([]T{})
+			return
+		}
+		if k > len(promises) {
+			reject(fmt.Errorf("%w: need %d of %d promises", ErrQuorumUnreachable, k, len(promises)))
+			return
+		}
+
+		type result struct {
+			idx int
+			val T
+			err error
+		}
+		results := make(chan result, len(promises))
+		for idx, p := range promises {
+			idx, p := idx, p
+			p.whenSettled(func() { results <- result{idx, p.value, p.reason} })
+		}
+
+		values := make([]T, 0, k)
+		errs := make([]error, len(promises))
+		rejected := 0
+		for {
+			res := <-results
+			if res.err == nil {
+				values = append(values, res.val)
+				if len(values) == k {
+					resolve(values)
+					return
+				}
+				continue
+			}
+			errs[res.idx] = res.err
+			rejected++
+			if rejected > len(promises)-k {
+				reject(fmt.Errorf("%w: %d of %d promises rejected: %w",
+					ErrQuorumUnreachable, rejected, len(promises), aggregate(errs)))
+				return
+			}
+		}
+	}, sources(promises)...)
+}
+
+// Any returns a Promise that fulfills with the value of the first of promises
+// to fulfill. It rejects with an *AggregateError holding the rejection
+// reasons of all promises once every one of them has rejected, or with
+// ErrNoPromises when there are none.
+func Any[T any](promises ...*Promise[T]) *Promise[T] {
+	return AnyWith(promises)
+}
+
+// AnyWith is like Any but accepts options. It honours WithContext and
+// WithTimeout.
+//
+// Once a promise fulfills, AnyWith gives up its claim on the others the same
+// way RaceWith does, with ErrRaceLost as their cause.
+func AnyWith[T any](promises []*Promise[T], opts ...Option) *Promise[T] {
+	promises = orRejected(promises)
+	if len(promises) == 0 {
+		return Reject[T](ErrNoPromises)
+	}
+	o := buildOptions(opts)
+	anyP := newNamedDerived[T](o.name, sources(promises)...)
+	anyP.start(func(resolve func(T), reject func(error)) {
+		type result struct {
+			idx int
+			val T
+			err error
+		}
+		results := make(chan result, len(promises))
+		for idx, p := range promises {
+			idx, p := idx, p
+			p.whenSettled(func() { results <- result{idx, p.value, p.reason} })
+		}
+
+		errs := make([]error, len(promises))
+		for idx := 0; idx < len(promises); idx++ {
+			res := <-results
+			if res.err == nil {
+				resolve(res.val)
+				anyP.releaseUpstream(ErrRaceLost)
+				return
+			}
+			errs[res.idx] = res.err
+		}
+		reject(aggregate(errs))
+	}, nil)
+	anyP.enforce(o)
+	return anyP
+}
+
+// Map calls fn on each of items and returns a Promise that fulfills with the
+// values of the resulting promises, in item order. It honours WithConcurrency,
+// WithFailFast, WithItemTimeout, WithContext and WithTimeout, and handles a
+// panic in fn as WithPanicHandler and WithPanicPolicy ask.
+//
+// By default Map rejects with the first rejection, wrapped in an IndexedError
+// holding the item's index, and makes no further calls to fn. With
+// WithFailFast(false), it calls fn on every item and rejects with an
+// *AggregateError holding the rejection reasons. Once the promise returned
+// by Map has settled, no further calls to fn are made; the promises already
+// returned by fn are left running.
+func Map[T, R any](items []T, fn func(T) *Promise[R], opts ...Option) *Promise[[]R] {
+	o := buildOptions(opts)
+	workers := o.concurrency
+	if workers <= 0 || workers > len(items) {
+		workers = len(items)
+	}
+
+	mapped := newNamedPromise[[]R](o.name)
+	mapped.start(func(resolve func([]R), reject func(error)) {
+		values := make([]R, len(items))
+		errs := make([]error, len(items))
+		jobs := make(chan int, len(items))
+		for idx := range items {
+			jobs <- idx
+		}
+		close(jobs)
+
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for idx := range jobs {
+					if mapped.isSettled() {
+						return
+					}
+					val, err := callMapped(fn, items[idx], o)
+					if err != nil && !o.collectAll {
+						reject(IndexedError{Index: idx, Err: err})
+						return
+					}
+					values[idx], errs[idx] = val, err
+				}
+			}()
+		}
+		wg.Wait()
+
+		if err := aggregate(errs); err != nil {
+			reject(err)
+			return
+		}
+		resolve(values)
+	}, nil)
+	mapped.enforce(o)
+	return mapped
+}
+
+// callMapped waits on the promise fn returns for item, turning a panic in fn,
+// handled as o asks, or a nil promise into an error.
+func callMapped[T, R any](fn func(T) *Promise[R], item T, o *options) (val R, err error) {
+	returned := false
+	defer func() {
+		if !returned {
+			err = panicError(recover(), o)
+		}
+	}()
+	p := fn(item)
+	returned = true
+	if p == nil {
+		return val, fmt.Errorf("map: fn returned a %w", ErrNilPromise)
+	}
+	return awaitItem(p, o.itemTimeout)
+}
+
+// FanOut returns a Promise that, once src fulfills, passes its value to each
+// of fns and fulfills with their results, in the order of fns. It rejects as
+// soon as src or any of the promises returned by fns rejects.
+func FanOut[T, R any](src *Promise[T], fns ...func(T) *Promise[R]) *Promise[[]R] {
+	if src == nil {
+		return Reject[[]R](ErrNilPromise)
+	}
+	return derive(func(resolve func([]R), reject func(error)) {
+		val, err := src.await()
+		if err != nil {
+			reject(err)
+			return
+		}
+		if len(fns) == 0 {
+			resolve([]R{})
+			return
+		}
+		branches := make([]*Promise[R], len(fns))
+		for idx, fn := range fns {
+			branches[idx] = fn(val)
+		}
+		values, err := All(branches...).await()
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(values)
+	}, src)
+}
+
+// FanIn returns a Promise that folds the values of promises into initial with
+// reduce, in the order of promises, and fulfills with the result. It rejects
+// with the first rejection among promises.
+func FanIn[T, R any](reduce func(acc R, val T) R, initial R, promises ...*Promise[T]) *Promise[R] {
+	promises = orRejected(promises)
+	if reduce == nil {
+		panic("reducer cannot be nil")
+	}
+	return derive(func(resolve func(R), reject func(error)) {
+		if len(promises) == 0 {
+			resolve(initial)
+			return
+		}
+		values, err := All(promises...).await()
+		if err != nil {
+			reject(err)
+			return
+		}
+		acc := initial
+		for _, val := range values {
+			acc = reduce(acc, val)
+		}
+		resolve(acc)
+	}, sources(promises)...)
+}
+
+// MapConcurrent is like Map for a synchronous fn: it calls fn on each of
+// items on at most n goroutines and fulfills with the results in item order.
+// It honours the same options as Map; by default it stops calling fn after
+// the first error and rejects with it. The context given to fn is cancelled
+// once the returned promise settles, so calls still running after a failure
+// can stop early. It derives from the context of WithContext, if given.
+func MapConcurrent[T, R any](items []T, n int, fn func(ctx context.Context, item T) (R, error), opts ...Option) *Promise[[]R] {
+	if fn == nil {
+		panic("fn cannot be nil")
+	}
+	parent := buildOptions(opts).ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	mapped := Map(items, func(item T) *Promise[R] {
+		val, err := fn(ctx, item)
+		if err != nil {
+			return Reject[R](err)
+		}
+		return Resolve(val)
+	}, append(opts, WithConcurrency(n))...)
+	go func() {
+		<-mapped.done
+		cancel()
+	}()
+	return mapped
+}
@@ -0,0 +1,359 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUntil(t *testing.T) {
+	calls := 0
+	p := Until(5*time.Millisecond, func() (int, bool, error) {
+		calls++
+		return calls, calls == 3, nil
+	})
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, res)
+}
+
+func TestUntil_Error(t *testing.T) {
+	p := Until(5*time.Millisecond, func() (int, bool, error) {
+		return 0, false, promiseError
+	})
+
+	_, err := p.Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestUntil_InvalidInterval(t *testing.T) {
+	called := false
+	_, err := Until(0, func() (int, bool, error) {
+		called = true
+		return 0, true, nil
+	}).Await()
+	assertEqual(t, "until: interval must be positive, got 0s", err.Error())
+	assert(t, !called, "cond should not be called")
+}
+
+func TestUntilWithin_Timeout(t *testing.T) {
+	p := UntilWithin(5*time.Millisecond, 30*time.Millisecond, func() (int, bool, error) {
+		return 0, false, nil
+	})
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrPollTimeout) && errors.Is(err, ErrTimeout), "expected a poll timeout")
+}
+
+func TestWhile(t *testing.T) {
+	p := While(1, func(v int) bool { return v < 1000 }, func(v int) *Promise[int] {
+		return Resolve(v * 2)
+	})
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1024, res)
+}
+
+func TestWhile_NilPromise(t *testing.T) {
+	_, err := While(0, func(v int) bool { return true }, func(v int) *Promise[int] { return nil }).Await()
+	assertEqual(t, ErrNilPromise, err)
+}
+
+func TestWhile_Rejection(t *testing.T) {
+	p := While(0, func(v int) bool { return true }, func(v int) *Promise[int] {
+		if v == 3 {
+			return Reject[int](promiseError)
+		}
+		return Resolve(v + 1)
+	})
+
+	_, err := p.Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestCoalesce(t *testing.T) {
+	l1 := Resolve("")
+	l2 := Reject[string](promiseError)
+	l3 := New(func(resolve func(string), reject func(error)) {
+		time.Sleep(10 * time.Millisecond)
+		resolve("from origin")
+	})
+
+	res, err := Coalesce(l1, l2, l3).Await()
+	assertNotErr(t, err)
+	assertEqual(t, "from origin", res)
+}
+
+func TestCoalesce_NoValue(t *testing.T) {
+	_, err := Coalesce(Resolve(0), Reject[int](promiseError)).Await()
+	assertEqual(t, ErrNoValue, err)
+
+	_, err = Coalesce[int]().Await()
+	assertEqual(t, ErrNoValue, err)
+}
+
+func sleepy[T any](d time.Duration, val T, err error) func() *Promise[T] {
+	return func() *Promise[T] {
+		return New(func(resolve func(T), reject func(error)) {
+			time.Sleep(d)
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(val)
+		})
+	}
+}
+
+func TestHedge(t *testing.T) {
+	start := time.Now()
+	p := Hedge(20*time.Millisecond,
+		sleepy(time.Second, "slow", nil),
+		sleepy(10*time.Millisecond, "hedge", nil),
+	)
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, "hedge", res)
+	assert(t, time.Since(start) < 500*time.Millisecond, "hedge should win before the slow request")
+}
+
+func TestHedge_CancelsLosers(t *testing.T) {
+	slow := NewWithContext(func(ctx context.Context, resolve func(string), reject func(error)) {
+		<-ctx.Done()
+	})
+	p := Hedge(10*time.Millisecond,
+		func() *Promise[string] { return slow },
+		sleepy(0, "hedge", nil),
+	)
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, "hedge", res)
+	_, err = slow.Await()
+	assert(t, errors.Is(err, ErrRaceLost), "the losing promise should be cancelled")
+}
+
+func TestHedge_NilPromise(t *testing.T) {
+	p := Hedge(time.Second,
+		func() *Promise[int] { return nil },
+		sleepy(0, 2, nil),
+	)
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+
+	_, err = Hedge(time.Second, func() *Promise[int] { return nil }).Await()
+	assertEqual(t, ErrNilPromise, err)
+}
+
+func TestHedge_AllRejected(t *testing.T) {
+	p := Hedge(time.Second,
+		sleepy(0, 0, promiseError),
+		sleepy(0, 0, promiseError),
+	)
+
+	_, err := p.Await()
+	assertEqual(t, promiseError, err)
+
+	_, err = Hedge[int](time.Millisecond).Await()
+	assertEqual(t, ErrNoPromises, err)
+}
+
+func TestQuorum(t *testing.T) {
+	p := Quorum(2,
+		sleepy(0, 1, nil)(),
+		sleepy(0, 0, promiseError)(),
+		sleepy(10*time.Millisecond, 3, nil)(),
+		sleepy(time.Second, 4, nil)(),
+	)
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, len(res))
+}
+
+func TestQuorum_Unreachable(t *testing.T) {
+	start := time.Now()
+	p := Quorum(2,
+		sleepy(0, 0, promiseError)(),
+		sleepy(0, 0, promiseError)(),
+		sleepy(time.Second, 3, nil)(),
+	)
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrQuorumUnreachable))
+	assert(t, errors.Is(err, promiseError), "expected the member rejection reasons")
+	assert(t, time.Since(start) < 500*time.Millisecond, "quorum should fail fast")
+
+	_, err = Quorum(3, Resolve(1)).Await()
+	assert(t, errors.Is(err, ErrQuorumUnreachable))
+}
+
+func TestAny(t *testing.T) {
+	p := Any(
+		sleepy(0, 0, promiseError)(),
+		sleepy(10*time.Millisecond, 2, nil)(),
+		sleepy(time.Second, 3, nil)(),
+	)
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+}
+
+func TestAny_AllRejected(t *testing.T) {
+	err1 := errors.New("Err 1")
+	p := Any(sleepy(10*time.Millisecond, 0, err1)(), sleepy(0, 0, promiseError)())
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, err1) && errors.Is(err, promiseError), "expected both rejection reasons")
+	var agg *AggregateError
+	assert(t, errors.As(err, &agg), "expected an *AggregateError")
+	var idxErr IndexedError
+	assert(t, errors.As(agg.Errors[1], &idxErr), "expected an IndexedError")
+	assertEqual(t, 1, idxErr.Index)
+	assertEqual(t, "2 errors occurred:\n\tpromise 0: Err 1\n\tpromise 1: Promise Error", err.Error())
+
+	_, err = Any[int]().Await()
+	assertEqual(t, ErrNoPromises, err)
+}
+
+func TestMap(t *testing.T) {
+	var running, peak int32
+	double := func(v int) *Promise[int] {
+		return New(func(resolve func(int), reject func(error)) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			resolve(v * 2)
+		})
+	}
+
+	res, err := Map([]int{1, 2, 3, 4, 5}, double, WithConcurrency(2)).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 5, len(res))
+	for idx, v := range res {
+		assertEqual(t, (idx+1)*2, v)
+	}
+	assert(t, atomic.LoadInt32(&peak) <= 2, "at most 2 calls should run at a time")
+}
+
+func TestMap_FailFast(t *testing.T) {
+	var calls int32
+	fail := func(v int) *Promise[int] {
+		atomic.AddInt32(&calls, 1)
+		if v%2 == 0 {
+			return Reject[int](fmt.Errorf("item %d: %w", v, promiseError))
+		}
+		return Resolve(v)
+	}
+
+	_, err := Map([]int{1, 2, 3, 4}, fail, WithConcurrency(1)).Await()
+	assertEqual(t, "promise 1: item 2: Promise Error", err.Error())
+	assertEqual(t, int32(2), atomic.LoadInt32(&calls))
+
+	_, err = Map([]int{1, 2, 3, 4}, fail, WithFailFast(false)).Await()
+	assertEqual(t, "2 errors occurred:\n\tpromise 1: item 2: Promise Error\n\tpromise 3: item 4: Promise Error", err.Error())
+}
+
+func TestFanOut(t *testing.T) {
+	user := Resolve("ada")
+	p := FanOut(user,
+		func(name string) *Promise[string] { return Resolve("profile:" + name) },
+		func(name string) *Promise[string] { return sleepy(5*time.Millisecond, "orders:"+name, nil)() },
+	)
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, len(res))
+	assertEqual(t, "profile:ada", res[0])
+	assertEqual(t, "orders:ada", res[1])
+
+	_, err = FanOut(user, func(string) *Promise[int] { return Reject[int](promiseError) }).Await()
+	assert(t, errors.Is(err, promiseError))
+}
+
+func TestFanIn(t *testing.T) {
+	sum := func(acc, v int) int { return acc + v }
+	res, err := FanIn(sum, 10, Resolve(1), sleepy(5*time.Millisecond, 2, nil)(), Resolve(3)).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 16, res)
+
+	_, err = FanIn(sum, 0, Resolve(1), Reject[int](promiseError)).Await()
+	assert(t, errors.Is(err, promiseError))
+}
+
+func TestMap_PanicNil(t *testing.T) {
+	_, err := Map([]int{1}, func(int) *Promise[int] { panic(nil) }).Await()
+	var panicErr *PanicError
+	assert(t, errors.As(err, &panicErr), "panic(nil) in fn should reject with a *PanicError")
+}
+
+func TestMap_PanicHandler(t *testing.T) {
+	var recovered atomic.Value
+	p := Map([]int{1}, func(int) *Promise[int] { panic("boom") },
+		WithPanicHandler(func(r any) { recovered.Store(r) }),
+		WithPanicPolicy(RepanicOnAwait))
+	<-p.Done()
+	assertEqual(t, "boom", recovered.Load())
+
+	defer func() {
+		panicErr, ok := recover().(*PanicError)
+		assert(t, ok, "Await should panic with a *PanicError")
+		assertEqual(t, "boom", panicErr.Value)
+	}()
+	p.Await()
+}
+
+func TestMapConcurrent(t *testing.T) {
+	var running, peak int32
+	square := func(ctx context.Context, v int) (int, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return v * v, nil
+	}
+
+	res, err := MapConcurrent([]int{1, 2, 3, 4, 5, 6}, 3, square).Await()
+	assertNotErr(t, err)
+	for idx, v := range res {
+		assertEqual(t, (idx+1)*(idx+1), v)
+	}
+	assert(t, atomic.LoadInt32(&peak) <= 3, "at most 3 calls should run at a time")
+}
+
+func TestMapConcurrent_StopsOnError(t *testing.T) {
+	started, stopped := make(chan struct{}), make(chan struct{})
+	fn := func(ctx context.Context, v int) (int, error) {
+		if v == 1 {
+			<-started
+			return 0, promiseError
+		}
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+		return 0, ctx.Err()
+	}
+
+	_, err := MapConcurrent([]int{1, 2}, 2, fn).Await()
+	assert(t, errors.Is(err, promiseError))
+	<-stopped
+}
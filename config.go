@@ -0,0 +1,130 @@
+package gopromise
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Config holds the package-wide defaults set by SetDefaults.
+type Config struct {
+	// Timeout is the default of WithTimeout: it applies to every promise that
+	// honours WithTimeout and is not given one. Zero means no timeout.
+	Timeout time.Duration
+	// PanicHandler, when set, is called with the value recovered from every
+	// executor or callback that panics, before its promise rejects. It is the
+	// default of WithPanicHandler.
+	PanicHandler func(recovered any)
+	// PanicPolicy is the default of WithPanicPolicy. It and PanicHandler also
+	// apply to callbacks that take no options, such as pipeline stages and
+	// the functions passed to MapStream or Supervise.
+	PanicPolicy PanicPolicy
+	// Strict is the default of WithStrict, and MisuseHandler the default of
+	// WithMisuseHandler.
+	Strict        bool
+	MisuseHandler func(err error)
+	// Scheduler runs the executors passed to New and the other constructors
+	// that take an executor. It is the default of WithScheduler. When nil,
+	// each executor runs on a goroutine of its own.
+	Scheduler Scheduler
+	// ContinuationBatch is the number of continuations (Then, Catch and the
+	// like) of a promise that are handed to the Scheduler as a single
+	// task once the promise settles, so that a promise with thousands of
+	// continuations does not flood the Scheduler. Zero or one hands each
+	// continuation on its own.
+	ContinuationBatch int
+}
+
+// settings holds the defaults set by SetDefaults together with the options
+// they amount to, which buildOptions hands out when given no options.
+type settings struct {
+	config  Config
+	options options
+}
+
+var (
+	defaults   atomic.Pointer[settings]
+	noDefaults settings
+)
+
+func currentSettings() *settings {
+	if s := defaults.Load(); s != nil {
+		return s
+	}
+	return &noDefaults
+}
+
+// SetDefaults makes c the defaults of promises created afterward. Promises
+// that already exist keep the defaults they were created with. Options passed
+// to a constructor or combinator override the defaults.
+func SetDefaults(c Config) {
+	defaults.Store(&settings{config: c, options: defaultOptions(c)})
+}
+
+// Defaults returns the current package-wide defaults.
+func Defaults() Config {
+	return currentSettings().config
+}
+
+// WithScheduler makes s run the executor of the promise instead of the
+// default Scheduler.
+func WithScheduler(s Scheduler) Option {
+	return func(o *options) {
+		o.scheduler = s
+	}
+}
+
+// PanicPolicy tells what happens once an executor or callback has panicked
+// and the panic handler, if any, has seen the recovered value.
+//
+// A callback registered with OnSettled, a hook or a journal sink has no
+// promise of its own to reject. A panic in one is raised again as a
+// *PanicError, once the promise has settled, on the goroutine that ran it,
+// unless a panic handler has taken it under RejectOnPanic.
+type PanicPolicy int
+
+const (
+	// RejectOnPanic rejects the promise with a *PanicError. It is the default.
+	RejectOnPanic PanicPolicy = iota
+	// RepanicOnAwait rejects the promise with a *PanicError, which Await,
+	// AwaitCtx and AwaitTimeout then panic with instead of returning it, on
+	// the awaiting goroutine. Promises derived from the promise, e.g. by
+	// Then or All, carry the *PanicError along and panic the same way.
+	RepanicOnAwait
+	// CrashOnPanic panics again with a *PanicError on the goroutine that
+	// panicked, which crashes the process like a panic outside a promise.
+	CrashOnPanic
+)
+
+// WithPanicPolicy makes policy, instead of the default PanicPolicy, decide
+// what happens when the executor of the promise panics.
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(o *options) {
+		o.panicPolicy = policy
+	}
+}
+
+// WithStrict makes a call to the resolve or reject function of the promise
+// after one of them has already been called panic with an error wrapping
+// ErrSettledTwice, instead of being ignored. It applies to the functions
+// handed to an executor and to those returned by Deferred.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithMisuseHandler is like WithStrict but hands the error to h instead of
+// panicking.
+func WithMisuseHandler(h func(err error)) Option {
+	return func(o *options) {
+		o.misuseHandler = h
+	}
+}
+
+// WithPanicHandler makes h, instead of the default PanicHandler, receive the
+// value recovered when the executor of the promise panics.
+func WithPanicHandler(h func(recovered any)) Option {
+	return func(o *options) {
+		o.panicHandler = h
+	}
+}
@@ -0,0 +1,150 @@
+package gopromise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingScheduler struct {
+	scheduled int32
+	err       error
+}
+
+func (s *countingScheduler) Schedule(task func()) error {
+	if s.err != nil {
+		return s.err
+	}
+	atomic.AddInt32(&s.scheduled, 1)
+	go task()
+	return nil
+}
+
+func TestSetDefaults_Timeout(t *testing.T) {
+	SetDefaults(Config{Timeout: 10 * time.Millisecond})
+	defer SetDefaults(Config{})
+
+	stuck := func(resolve func(int), reject func(error)) {
+		time.Sleep(100 * time.Millisecond)
+		resolve(1)
+	}
+	_, err := New(stuck).Await()
+	assert(t, errors.Is(err, ErrTimeout), "expected a timeout")
+
+	res, err := New(stuck, WithTimeout(0)).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+}
+
+func TestSetDefaults_PanicHandler(t *testing.T) {
+	var recovered atomic.Value
+	SetDefaults(Config{PanicHandler: func(r any) { recovered.Store(r) }})
+	defer SetDefaults(Config{})
+
+	_, err := New(func(resolve func(int), reject func(error)) {
+		panic("boom")
+	}).Await()
+	assertEqual(t, "boom", err.Error())
+	assertEqual(t, "boom", recovered.Load())
+
+	var local any
+	New(func(resolve func(int), reject func(error)) {
+		panic("local")
+	}, WithPanicHandler(func(r any) { local = r })).Await()
+	assertEqual(t, "local", local)
+}
+
+func TestSetDefaults_Scheduler(t *testing.T) {
+	sched := &countingScheduler{}
+	SetDefaults(Config{Scheduler: sched})
+	defer SetDefaults(Config{})
+
+	res, err := New(func(resolve func(int), reject func(error)) { resolve(1) }).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+	assertEqual(t, int32(1), atomic.LoadInt32(&sched.scheduled))
+
+	full := &countingScheduler{err: errors.New("scheduler full")}
+	_, err = New(func(resolve func(int), reject func(error)) { resolve(1) }, WithScheduler(full)).Await()
+	assertEqual(t, full.err, err)
+}
+
+func TestSetDefaults_ContinuationBatch(t *testing.T) {
+	sched := &countingScheduler{}
+	SetDefaults(Config{Scheduler: sched, ContinuationBatch: 100})
+	defer SetDefaults(Config{})
+
+	src, resolve, _ := Deferred[int]()
+	derived := make([]*Promise[int], 1000)
+	for i := range derived {
+		derived[i] = src.Then(func(val int) int { return val + 1 })
+	}
+	resolve(1)
+	for _, p := range derived {
+		assertEqual(t, 2, p.MustAwait())
+	}
+	assertEqual(t, int32(10), atomic.LoadInt32(&sched.scheduled))
+}
+
+func TestWithStrict(t *testing.T) {
+	_, resolve, reject := Deferred[int](WithStrict())
+	resolve(1)
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			assert(t, errors.Is(err, ErrSettledTwice), "a second call should panic with ErrSettledTwice")
+			assertEqual(t, "promise settled twice: reject called after resolve", err.Error())
+		}()
+		reject(promiseError)
+	}()
+
+	misused := make(chan error, 1)
+	p := New(func(resolve func(int), reject func(error)) {
+		reject(promiseError)
+		resolve(2)
+	}, WithMisuseHandler(func(err error) { misused <- err }), WithName("twice"))
+	_, err := p.Await()
+	assertEqual(t, promiseError, err)
+	misuse := <-misused
+	assert(t, errors.Is(misuse, ErrSettledTwice))
+	assertEqual(t, `promise "twice": promise settled twice: resolve called after reject`, misuse.Error())
+
+	_, resolve, _ = Deferred[int](WithStrict(), WithTimeout(time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+	resolve(1)
+}
+
+func TestWithPanicPolicy_RepanicOnAwait(t *testing.T) {
+	p := New(func(resolve func(int), reject func(error)) {
+		panic("boom")
+	}, WithPanicPolicy(RepanicOnAwait))
+	<-p.Done()
+	_, err, _ := p.TryAwait()
+	assertErr(t, err)
+
+	for _, awaited := range []*Promise[int]{p, p.Then(func(val int) int { return val })} {
+		func() {
+			defer func() {
+				panicErr, ok := recover().(*PanicError)
+				assert(t, ok, "Await should panic with a *PanicError")
+				assertEqual(t, "boom", panicErr.Value)
+			}()
+			awaited.Await()
+		}()
+	}
+}
+
+func TestWithPanicPolicy_CrashOnPanic(t *testing.T) {
+	var handled any
+	defer func() {
+		panicErr, ok := recover().(*PanicError)
+		assert(t, ok, "the panic should carry on as a *PanicError")
+		assertEqual(t, "boom", panicErr.Value)
+		assertEqual(t, "boom", handled)
+	}()
+	New(func(resolve func(int), reject func(error)) {
+		panic("boom")
+	}, WithPanicPolicy(CrashOnPanic), WithPanicHandler(func(r any) { handled = r }), WithInline())
+	t.Fatal("New should have panicked")
+}
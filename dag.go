@@ -0,0 +1,303 @@
+package gopromise
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNodeTimeout is wrapped, together with a *TimeoutError, by the rejection
+// reason of a DAG node attempt that did not settle within the node's timeout.
+var ErrNodeTimeout = errors.New("dag node timed out")
+
+// NodeFunc produces the promise of a DAG node. It receives the values of the
+// node's dependencies keyed by node name.
+type NodeFunc func(deps map[string]any) *Promise[any]
+
+// Dag builds a graph of named, promise-producing nodes. Each node runs as soon
+// as all of its dependencies have fulfilled; when a node fails, every node
+// that depends on it fails too without running.
+type Dag struct {
+	nodes map[string]*DagNode
+	order []string
+	err   error
+}
+
+// DagNode is a node of a Dag. Its methods configure the node and return it so
+// calls can be chained.
+type DagNode struct {
+	name     string
+	deps     []string
+	fn       NodeFunc
+	attempts int
+	backoff  Backoff
+	timeout  time.Duration
+}
+
+// NewDag returns an empty Dag.
+func NewDag() *Dag {
+	return &Dag{nodes: make(map[string]*DagNode)}
+}
+
+// Node adds a node named name that runs fn once every node listed in deps has
+// fulfilled.
+func (d *Dag) Node(name string, fn NodeFunc, deps ...string) *DagNode {
+	n := &DagNode{name: name, deps: deps, fn: fn, attempts: 1}
+	if fn == nil && d.err == nil {
+		d.err = fmt.Errorf("dag: node %q has no function", name)
+	}
+	if _, ok := d.nodes[name]; ok && d.err == nil {
+		d.err = fmt.Errorf("dag: duplicate node %q", name)
+	}
+	d.nodes[name] = n
+	d.order = append(d.order, name)
+	return n
+}
+
+// Retry makes the node retry a rejected attempt up to retries more times,
+// waiting backoff between attempts.
+func (n *DagNode) Retry(retries int, backoff time.Duration) *DagNode {
+	return n.RetryBackoff(retries, func(int) time.Duration { return backoff })
+}
+
+// RetryBackoff is like Retry but asks backoff how long to wait before each
+// retry, e.g. to apply FullJitter.
+func (n *DagNode) RetryBackoff(retries int, backoff Backoff) *DagNode {
+	n.attempts = retries + 1
+	n.backoff = backoff
+	return n
+}
+
+// Timeout rejects each attempt of the node with an error wrapping
+// ErrNodeTimeout when it has not settled within d.
+func (n *DagNode) Timeout(d time.Duration) *DagNode {
+	n.timeout = d
+	return n
+}
+
+// DagRun is a running Dag.
+type DagRun struct {
+	nodes map[string]*Promise[any]
+	all   *Promise[map[string]any]
+}
+
+// Node returns the promise of the node named name, or nil when there is no
+// such node.
+func (r *DagRun) Node(name string) *Promise[any] {
+	return r.nodes[name]
+}
+
+// Result returns a promise fulfilled with the values of every node, keyed by
+// name, once the whole graph has fulfilled. When a node fails, it waits for
+// the rest of the graph to settle and rejects with a *DagError describing the
+// partial result.
+func (r *DagRun) Result() *Promise[map[string]any] {
+	return r.all
+}
+
+// Run validates the graph and starts it. It returns an error when a node was
+// declared twice, depends on an unknown node, or the graph has a cycle.
+func (d *Dag) Run() (*DagRun, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	r := &DagRun{nodes: make(map[string]*Promise[any], len(d.nodes))}
+	var mutex sync.Mutex
+	skipped := make(map[string]bool)
+	var start func(name string) *Promise[any]
+	start = func(name string) *Promise[any] {
+		if p, ok := r.nodes[name]; ok {
+			return p
+		}
+		n := d.nodes[name]
+		deps := make([]*Promise[any], len(n.deps))
+		for idx, dep := range n.deps {
+			deps[idx] = start(dep)
+		}
+		p := run(func(resolve func(any), reject func(error)) {
+			values := make(map[string]any, len(deps))
+			for idx, dep := range deps {
+				val, err := dep.await()
+				if err != nil {
+					mutex.Lock()
+					skipped[n.name] = true
+					mutex.Unlock()
+					reject(fmt.Errorf("dag: dependency %q of %q failed: %w", n.deps[idx], n.name, err))
+					return
+				}
+				values[n.deps[idx]] = val
+			}
+			val, err := n.execute(values)
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(val)
+		})
+		r.nodes[name] = p
+		return p
+	}
+	for _, name := range d.order {
+		start(name)
+	}
+
+	r.all = run(func(resolve func(map[string]any), reject func(error)) {
+		result := make(map[string]any, len(d.order))
+		var dagErr *DagError
+		for _, name := range d.order {
+			val, err := r.nodes[name].await()
+			if err == nil {
+				result[name] = val
+				continue
+			}
+			if dagErr == nil {
+				dagErr = &DagError{Completed: result, Failures: make(map[string]error)}
+			}
+			mutex.Lock()
+			skip := skipped[name]
+			mutex.Unlock()
+			if skip {
+				dagErr.Skipped = append(dagErr.Skipped, name)
+				continue
+			}
+			if dagErr.Failed == "" {
+				dagErr.Failed, dagErr.Err = name, err
+			}
+			dagErr.Failures[name] = err
+		}
+		if dagErr != nil {
+			reject(dagErr)
+			return
+		}
+		resolve(result)
+	})
+	return r, nil
+}
+
+// DagError is the rejection reason of DagRun.Result when part of the graph
+// failed. It tells which nodes completed, which failed on their own, and
+// which were skipped because one of their dependencies failed.
+type DagError struct {
+	// Completed holds the values of the nodes that fulfilled.
+	Completed map[string]any
+	// Failed names the first node, in declaration order, that failed on its
+	// own, and Err is its rejection reason.
+	Failed string
+	Err    error
+	// Failures holds the rejection reason of every node that failed on its own.
+	Failures map[string]error
+	// Skipped names the nodes that never ran because a dependency failed.
+	Skipped []string
+}
+
+func (e *DagError) Error() string {
+	completed := make([]string, 0, len(e.Completed))
+	for name := range e.Completed {
+		completed = append(completed, name)
+	}
+	sort.Strings(completed)
+	return fmt.Sprintf("dag: node %q failed: %v (completed: [%s], skipped: [%s])",
+		e.Failed, e.Err, strings.Join(completed, " "), strings.Join(e.Skipped, " "))
+}
+
+// Unwrap returns the rejection reasons of the nodes that failed on their
+// own, starting with Err, so that errors.Is and errors.As see all of them.
+func (e *DagError) Unwrap() []error {
+	names := make([]string, 0, len(e.Failures))
+	for name := range e.Failures {
+		if name != e.Failed {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	errs := make([]error, 0, len(names)+1)
+	errs = append(errs, e.Err)
+	for _, name := range names {
+		errs = append(errs, e.Failures[name])
+	}
+	return errs
+}
+
+func (d *Dag) validate() error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(d.nodes))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dag: cycle through node %q", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range d.nodes[name].deps {
+			if _, ok := d.nodes[dep]; !ok {
+				return fmt.Errorf("dag: node %q depends on unknown node %q", name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, name := range d.order {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute runs the node's attempts in turn until one fulfills.
+func (n *DagNode) execute(deps map[string]any) (val any, err error) {
+	for attempt := 0; attempt < n.attempts; attempt++ {
+		if attempt > 0 && n.backoff != nil {
+			time.Sleep(n.backoff(attempt - 1))
+		}
+		val, err = n.attempt(deps)
+		if err == nil {
+			return val, nil
+		}
+	}
+	return nil, err
+}
+
+func (n *DagNode) attempt(deps map[string]any) (any, error) {
+	p := n.fn(deps)
+	if p == nil {
+		return nil, fmt.Errorf("dag: node %q returned a %w", n.name, ErrNilPromise)
+	}
+	if n.timeout <= 0 {
+		return p.await()
+	}
+
+	type result struct {
+		val any
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		val, err := p.await()
+		done <- result{val, err}
+	}()
+	timer := time.NewTimer(n.timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.val, res.err
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: %w", ErrNodeTimeout, &TimeoutError{After: n.timeout, PromiseName: n.name})
+	}
+}
@@ -0,0 +1,135 @@
+package gopromise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func constNode(val any) NodeFunc {
+	return func(map[string]any) *Promise[any] { return Resolve(val) }
+}
+
+func TestDag_Run(t *testing.T) {
+	d := NewDag()
+	d.Node("a", constNode(1))
+	d.Node("b", constNode(2))
+	d.Node("c", func(deps map[string]any) *Promise[any] {
+		return Resolve[any](deps["a"].(int) + deps["b"].(int))
+	}, "a", "b")
+	d.Node("d", func(deps map[string]any) *Promise[any] {
+		return Resolve[any](deps["c"].(int) * 10)
+	}, "c")
+
+	r, err := d.Run()
+	assertNil(t, err)
+
+	val, err := r.Node("d").Await()
+	assertNotErr(t, err)
+	assertEqual(t, 30, val)
+
+	all, err := r.Result().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 4, len(all))
+	assertEqual(t, 3, all["c"])
+}
+
+func TestDag_FailurePropagates(t *testing.T) {
+	ran := false
+	d := NewDag()
+	d.Node("a", func(map[string]any) *Promise[any] { return Reject[any](promiseError) })
+	d.Node("b", func(map[string]any) *Promise[any] {
+		ran = true
+		return Resolve[any](nil)
+	}, "a")
+
+	r, err := d.Run()
+	assertNil(t, err)
+
+	_, err = r.Node("b").Await()
+	assert(t, errors.Is(err, promiseError), "dependent should fail with the dependency's error")
+	assert(t, !ran, "dependent should not run")
+
+	_, err = r.Result().Await()
+	assert(t, errors.Is(err, promiseError))
+}
+
+func TestDag_RetryAndTimeout(t *testing.T) {
+	var calls int32
+	d := NewDag()
+	d.Node("flaky", func(map[string]any) *Promise[any] {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return Reject[any](promiseError)
+		}
+		return Resolve[any]("ok")
+	}).RetryBackoff(2, func(attempt int) time.Duration {
+		return FullJitter(time.Millisecond, 5*time.Millisecond, attempt)
+	})
+	d.Node("slow", func(map[string]any) *Promise[any] {
+		return New(func(resolve func(any), reject func(error)) {
+			time.Sleep(100 * time.Millisecond)
+			resolve(nil)
+		})
+	}).Timeout(10 * time.Millisecond)
+
+	r, err := d.Run()
+	assertNil(t, err)
+
+	val, err := r.Node("flaky").Await()
+	assertNotErr(t, err)
+	assertEqual(t, "ok", val)
+
+	_, err = r.Node("slow").Await()
+	assert(t, errors.Is(err, ErrNodeTimeout))
+}
+
+func TestDag_Invalid(t *testing.T) {
+	d := NewDag()
+	d.Node("a", constNode(1), "b")
+	d.Node("b", constNode(2), "a")
+	_, err := d.Run()
+	assertErr(t, err)
+
+	d = NewDag()
+	d.Node("a", constNode(1), "missing")
+	_, err = d.Run()
+	assertErr(t, err)
+}
+
+func TestDag_PartialResult(t *testing.T) {
+	d := NewDag()
+	d.Node("cart", constNode("cart"))
+	d.Node("taxes", constNode(0.2), "cart")
+	d.Node("shipping", func(map[string]any) *Promise[any] {
+		return Reject[any](promiseError)
+	}, "cart")
+	d.Node("total", constNode(42), "taxes", "shipping")
+
+	r, err := d.Run()
+	assertNil(t, err)
+
+	_, err = r.Result().Await()
+	var dagErr *DagError
+	assert(t, errors.As(err, &dagErr), "expected a *DagError")
+	assert(t, errors.Is(err, promiseError))
+	assertEqual(t, "shipping", dagErr.Failed)
+	assertEqual(t, 2, len(dagErr.Completed))
+	assertEqual(t, 0.2, dagErr.Completed["taxes"])
+	assertEqual(t, 1, len(dagErr.Skipped))
+	assertEqual(t, "total", dagErr.Skipped[0])
+}
+
+func TestDagError_UnwrapsEveryFailure(t *testing.T) {
+	other := errors.New("other failure")
+	d := NewDag()
+	d.Node("a", func(map[string]any) *Promise[any] { return Reject[any](promiseError) })
+	d.Node("b", func(map[string]any) *Promise[any] { return Reject[any](other) })
+
+	r, err := d.Run()
+	assertNil(t, err)
+
+	_, err = r.Result().Await()
+	assert(t, errors.Is(err, promiseError), "DagError should wrap the first failure")
+	assert(t, errors.Is(err, other), "DagError should wrap every failure")
+}
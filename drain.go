@@ -0,0 +1,134 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDraining is the rejection reason of promises created after Drain was
+// called.
+var ErrDraining = errors.New("promises are draining")
+
+// inflight links a promise started with options into the list of promises
+// Drain waits for. It is part of the promise, so tracking allocates nothing.
+type inflight struct {
+	prev, next *inflight
+	owner      interface{ pendingSince() (string, time.Time) }
+}
+
+var drainState struct {
+	mutex    sync.Mutex
+	draining bool
+	count    int
+	// head is the sentinel of the circular list of in-flight promises.
+	head    inflight
+	drained chan struct{}
+}
+
+// track registers p as in flight, or reports false when draining has begun.
+func (p *Promise[T]) track() bool {
+	drainState.mutex.Lock()
+	defer drainState.mutex.Unlock()
+	if drainState.draining {
+		return false
+	}
+	head := &drainState.head
+	if head.next == nil {
+		head.prev, head.next = head, head
+	}
+	f := &p.flight
+	f.owner = p
+	f.prev, f.next = head, head.next
+	head.next.prev = f
+	head.next = f
+	drainState.count++
+	return true
+}
+
+// untrack removes f, which track linked, from the in-flight list.
+func untrack(f *inflight) {
+	drainState.mutex.Lock()
+	defer drainState.mutex.Unlock()
+	f.prev.next = f.next
+	f.next.prev = f.prev
+	f.prev, f.next = nil, nil
+	drainState.count--
+	if drainState.count == 0 && drainState.drained != nil {
+		close(drainState.drained)
+		drainState.drained = nil
+	}
+}
+
+func (p *Promise[T]) pendingSince() (string, time.Time) {
+	return p.name, p.created
+}
+
+// DrainError is returned by Drain when promises were still pending as its
+// context expired.
+type DrainError struct {
+	// Pending describes each promise that did not settle in time.
+	Pending []string
+	Err     error
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("drain: %d promises still pending: %s", len(e.Pending), strings.Join(e.Pending, ", "))
+}
+
+func (e *DrainError) Unwrap() error {
+	return e.Err
+}
+
+// Drain prepares the process for shutdown: from now on, promises created by
+// New and the other executor-based constructors are rejected with ErrDraining
+// without running, and Drain waits for the promises already in flight to
+// settle. When ctx is done first, Drain returns a *DrainError describing the
+// promises that never settled. Promises derived from others by combinators
+// are not tracked. StopDraining lets promises run again.
+func Drain(ctx context.Context) error {
+	drainState.mutex.Lock()
+	drainState.draining = true
+	if drainState.count == 0 {
+		drainState.mutex.Unlock()
+		return nil
+	}
+	if drainState.drained == nil {
+		drainState.drained = make(chan struct{})
+	}
+	drained := drainState.drained
+	drainState.mutex.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	var pending []string
+	drainState.mutex.Lock()
+	for f := drainState.head.next; f != nil && f != &drainState.head; f = f.next {
+		name, created := f.owner.pendingSince()
+		if name == "" {
+			name = "unnamed promise"
+		}
+		pending = append(pending, fmt.Sprintf("%s (pending for %s)", name, time.Since(created).Round(time.Millisecond)))
+	}
+	drainState.mutex.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	return &DrainError{Pending: pending, Err: ctx.Err()}
+}
+
+// StopDraining undoes Drain: promises created from now on run again. It is
+// meant for tests and for processes that call off a shutdown. A Drain still
+// waiting keeps waiting for the promises that were in flight.
+func StopDraining() {
+	drainState.mutex.Lock()
+	defer drainState.mutex.Unlock()
+	drainState.draining = false
+}
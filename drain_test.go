@@ -0,0 +1,87 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	defer StopDraining()
+
+	inflight := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(20 * time.Millisecond)
+		resolve(1)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	assertNil(t, Drain(ctx))
+
+	res, err := inflight.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+
+	_, err = New(func(resolve func(int), reject func(error)) {
+		resolve(2)
+	}).Await()
+	assertEqual(t, ErrDraining, err)
+}
+
+func TestDrain_Expired(t *testing.T) {
+	defer StopDraining()
+
+	release := make(chan struct{})
+	defer close(release)
+	NewJournaled("stuck-job", "", func(resolve func(int), reject func(error)) {
+		<-release
+		resolve(1)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := Drain(ctx)
+
+	var drainErr *DrainError
+	assert(t, errors.As(err, &drainErr), "expected a *DrainError")
+	assert(t, errors.Is(err, context.DeadlineExceeded))
+	assert(t, strings.Contains(err.Error(), "stuck-job"))
+}
+
+func TestDrain_ConcurrentStarts(t *testing.T) {
+	defer StopDraining()
+
+	var mutex sync.Mutex
+	var started []*Promise[int]
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p := New(func(resolve func(int), reject func(error)) { resolve(1) }, WithName("job"))
+				mutex.Lock()
+				started = append(started, p)
+				mutex.Unlock()
+			}
+		}()
+	}
+	assertNil(t, Drain(context.Background()))
+	mutex.Lock()
+	for _, p := range started {
+		assert(t, p.State() != Pending, "Drain returned before a tracked promise settled")
+	}
+	mutex.Unlock()
+	wg.Wait()
+}
+
+func TestStopDraining(t *testing.T) {
+	assertNil(t, Drain(context.Background()))
+	StopDraining()
+	res, err := New(func(resolve func(int), reject func(error)) { resolve(1) }).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+}
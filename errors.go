@@ -0,0 +1,216 @@
+package gopromise
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// ErrTimeout is matched under errors.Is by every error reporting that a
+// promise, or a wait on one, did not settle in time. The rejection reasons
+// themselves are *TimeoutError values.
+var ErrTimeout = errors.New("promise timed out")
+
+// ErrCancelled is the rejection reason of a promise cancelled without a
+// cause. Promises cancelled with a cause reject with an error that matches
+// both ErrCancelled and the cause under errors.Is.
+var ErrCancelled = errors.New("promise cancelled")
+
+// ErrExecutorReturnedWithoutSettling is the rejection reason of a promise
+// whose executor returned without calling resolve or reject, unless the
+// promise was created WithSettleLater.
+var ErrExecutorReturnedWithoutSettling = errors.New("executor returned without settling the promise")
+
+// ErrNilPromise is the rejection reason of a promise derived from a nil
+// promise, and of the inputs of a combinator that are nil.
+var ErrNilPromise = errors.New("nil promise")
+
+// ErrSettledTwice is wrapped by the error reported by a strict promise, see
+// WithStrict, when its resolve or reject function is called a second time.
+var ErrSettledTwice = errors.New("promise settled twice")
+
+// TimeoutError is the rejection reason of a promise that did not settle in
+// time. It matches ErrTimeout under errors.Is.
+type TimeoutError struct {
+	// After is the time the promise was given to settle.
+	After time.Duration
+	// PromiseName is the name of the promise that timed out, if it has one.
+	PromiseName string
+}
+
+func (e *TimeoutError) Error() string {
+	if e.PromiseName != "" {
+		return fmt.Sprintf("promise %q timed out after %v", e.PromiseName, e.After)
+	}
+	return fmt.Sprintf("promise timed out after %v", e.After)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrTimeout
+}
+
+type cancelError struct {
+	cause error
+}
+
+func (e *cancelError) Error() string {
+	return ErrCancelled.Error() + ": " + e.cause.Error()
+}
+
+func (e *cancelError) Is(target error) bool {
+	return target == ErrCancelled
+}
+
+func (e *cancelError) Unwrap() error {
+	return e.cause
+}
+
+// ConversionError is the rejection reason of As and Catch when the value of
+// their input does not have the requested type.
+type ConversionError struct {
+	// Value is the value that could not be converted.
+	Value any
+	// Want is the requested type.
+	Want reflect.Type
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("cannot convert %T to %v", e.Value, e.Want)
+}
+
+// PanicError is the rejection reason of a promise whose executor or callback
+// panicked. Value is the value passed to panic, nil for panic(nil). When it
+// is an error, the PanicError wraps it, so it matches under errors.Is and
+// errors.As. Printed with %+v, a PanicError is followed by the stack of the
+// goroutine that panicked.
+type PanicError struct {
+	Value   any
+	stack   []byte
+	repanic bool
+}
+
+func (e *PanicError) Error() string {
+	switch v := e.Value.(type) {
+	case nil:
+		return "panic(nil)"
+	case error:
+		return v.Error()
+	}
+	return fmt.Sprintf("%+v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// StackTrace returns the stack of the goroutine that panicked, as formatted
+// by runtime/debug.Stack.
+func (e *PanicError) StackTrace() string {
+	return string(e.stack)
+}
+
+func (e *PanicError) Format(f fmt.State, verb rune) {
+	if verb == 'v' && f.Flag('+') {
+		fmt.Fprintf(f, "%s\n%s", e.Error(), e.stack)
+		return
+	}
+	fmt.Fprintf(f, fmt.FormatString(f, verb), e.Error())
+}
+
+// panicError turns the value recovered from a panic in a callback into a
+// rejection reason, handling it as o asks, or as Defaults asks when o is nil.
+// It must be called from the deferred function that recovered, so that the
+// stack still shows where the panic happened.
+func panicError(recovered any, o *options) error {
+	handler, policy := panicSettings(o)
+	err := handlePanic(recovered, handler, policy)
+	if policy == CrashOnPanic {
+		panic(err)
+	}
+	return err
+}
+
+// panicSettings returns the panic handler and policy of o, or the defaults
+// when o is nil.
+func panicSettings(o *options) (func(recovered any), PanicPolicy) {
+	if o != nil {
+		return o.panicHandler, o.panicPolicy
+	}
+	c := Defaults()
+	return c.PanicHandler, c.PanicPolicy
+}
+
+// handlePanic is like panicError for the given handler and policy, but leaves
+// it to the caller to panic again under CrashOnPanic, so it can settle its
+// promise first.
+func handlePanic(recovered any, handler func(recovered any), policy PanicPolicy) *PanicError {
+	if handler != nil {
+		handler(recovered)
+	}
+	return &PanicError{Value: recovered, stack: debug.Stack(), repanic: policy == RepanicOnAwait}
+}
+
+// repanic panics with the *PanicError in the chain of err, if there is one
+// and it was created under RepanicOnAwait.
+func repanic(err error) {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) && panicErr.repanic {
+		panic(panicErr)
+	}
+}
+
+// IndexedError is a rejection reason together with the position of the
+// promise that rejected with it among the inputs of a combinator.
+type IndexedError struct {
+	Index int
+	Err   error
+}
+
+func (e IndexedError) Error() string {
+	return fmt.Sprintf("promise %d: %v", e.Index, e.Err)
+}
+
+func (e IndexedError) Unwrap() error {
+	return e.Err
+}
+
+// AggregateError is the rejection reason of a combinator that collects the
+// rejections of several of its inputs. Each member error is matched under
+// errors.Is and errors.As.
+type AggregateError struct {
+	// Errors holds the rejection reasons, in input order. Each of them is an
+	// IndexedError telling which input it comes from.
+	Errors []error
+}
+
+// aggregate returns an *AggregateError holding the non-nil errors of errs,
+// which is indexed by input position, or nil when there are none.
+func aggregate(errs []error) error {
+	agg := &AggregateError{}
+	for idx, err := range errs {
+		if err != nil {
+			agg.Errors = append(agg.Errors, IndexedError{Index: idx, Err: err})
+		}
+	}
+	if len(agg.Errors) == 0 {
+		return nil
+	}
+	return agg
+}
+
+func (e *AggregateError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors occurred:", len(e.Errors))
+	for _, err := range e.Errors {
+		fmt.Fprintf(&b, "\n\t%v", err)
+	}
+	return b.String()
+}
+
+func (e *AggregateError) Unwrap() []error {
+	return e.Errors
+}
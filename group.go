@@ -0,0 +1,89 @@
+package gopromise
+
+import (
+	"errors"
+	"sync"
+)
+
+// Group tracks a set of promises of the same type, as a promise-native
+// replacement for sync.WaitGroup bookkeeping. The zero value is an empty
+// group ready to use.
+type Group[T any] struct {
+	mutex    sync.Mutex
+	promises []*Promise[T]
+}
+
+// Add adds p to g. A nil p is added as a member rejected with ErrNilPromise.
+func (g *Group[T]) Add(p *Promise[T]) {
+	p = orReject(p)
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.promises = append(g.promises, p)
+}
+
+// Go runs fn on its own goroutine as a new member of g and returns its
+// promise.
+func (g *Group[T]) Go(fn func() (T, error)) *Promise[T] {
+	p := New(func(resolve func(T), reject func(error)) {
+		val, err := fn()
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(val)
+	})
+	g.Add(p)
+	return p
+}
+
+// Len returns the number of members of g.
+func (g *Group[T]) Len() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return len(g.promises)
+}
+
+// Settled returns the number of members of g that have settled.
+func (g *Group[T]) Settled() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	settled := 0
+	for _, p := range g.promises {
+		if p.isSettled() {
+			settled++
+		}
+	}
+	return settled
+}
+
+// Pending returns the number of members of g that have not settled yet.
+func (g *Group[T]) Pending() int {
+	g.mutex.Lock()
+	n := len(g.promises)
+	g.mutex.Unlock()
+	return n - g.Settled()
+}
+
+// Wait blocks until every member of g, including members added while it
+// waits, has settled. It returns the values of the members in the order they
+// were added, with the zero value for rejected members, and the rejection
+// reasons joined, or nil when every member fulfilled.
+func (g *Group[T]) Wait() ([]T, error) {
+	var values []T
+	var errs []error
+	for {
+		g.mutex.Lock()
+		members := g.promises[len(values):]
+		g.mutex.Unlock()
+		if len(members) == 0 {
+			return values, errors.Join(errs...)
+		}
+		for _, p := range members {
+			val, err := p.await()
+			values = append(values, val)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package gopromise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	var g Group[int]
+	release := make(chan struct{})
+	g.Add(Resolve(1))
+	g.Go(func() (int, error) {
+		<-release
+		return 2, nil
+	})
+	g.Go(func() (int, error) { return 0, promiseError })
+
+	assertEqual(t, 3, g.Len())
+	for g.Settled() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	assertEqual(t, 1, g.Pending())
+	close(release)
+
+	values, err := g.Wait()
+	assert(t, errors.Is(err, promiseError))
+	assertEqual(t, 3, len(values))
+	assertEqual(t, 1, values[0])
+	assertEqual(t, 2, values[1])
+	assertEqual(t, 0, g.Pending())
+}
+
+func TestGroup_Empty(t *testing.T) {
+	var g Group[string]
+	values, err := g.Wait()
+	assertNil(t, err)
+	assertEqual(t, 0, len(values))
+}
+
+func TestGroup_AddNil(t *testing.T) {
+	var g Group[int]
+	g.Add(Resolve(1))
+	g.Add(nil)
+	assertEqual(t, 2, g.Settled())
+	values, err := g.Wait()
+	assert(t, errors.Is(err, ErrNilPromise))
+	assertEqual(t, 2, len(values))
+}
@@ -0,0 +1,149 @@
+package gopromise
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PromiseInfo describes a promise to lifecycle hooks.
+type PromiseInfo struct {
+	// ID identifies the promise across the hooks it triggers. It is zero for
+	// promises created before any hooks were registered.
+	ID uint64
+	// Name is the name given with WithName, if any.
+	Name string
+	// Site is the file:line of the call outside this package that created
+	// the promise. It is empty when ID is zero.
+	Site    string
+	Created time.Time
+	// Duration is the time from creation to settlement. It is zero in
+	// OnCreate.
+	Duration time.Duration
+	// Err is the rejection reason, for OnReject, OnPanic and OnCancel.
+	Err error
+}
+
+// Hooks are called for every promise in the process once registered with
+// RegisterHooks. Any of them may be nil. Exactly one of OnResolve, OnReject,
+// OnPanic and OnCancel is called when a promise settles: a rejection caused
+// by a panic in the executor goes to OnPanic only, and a cancellation to
+// OnCancel only.
+//
+// Hooks run on the goroutine that creates or settles the promise, so they
+// should be fast and safe for concurrent use.
+type Hooks struct {
+	OnCreate  func(info PromiseInfo)
+	OnResolve func(info PromiseInfo)
+	OnReject  func(info PromiseInfo)
+	OnPanic   func(info PromiseInfo)
+	OnCancel  func(info PromiseInfo)
+}
+
+var (
+	hooksMutex sync.Mutex
+	hooksByID  = make(map[uint64]Hooks)
+	nextHooks  uint64
+	// hooks holds a snapshot of hooksByID, or nil when it is empty.
+	hooks    atomic.Pointer[[]Hooks]
+	promises atomic.Uint64
+)
+
+// RegisterHooks registers h for every promise created or settled from now on.
+// It returns a function that removes the registration.
+func RegisterHooks(h Hooks) (unregister func()) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	id := nextHooks
+	nextHooks++
+	hooksByID[id] = h
+	storeHooks()
+	return func() {
+		hooksMutex.Lock()
+		defer hooksMutex.Unlock()
+		delete(hooksByID, id)
+		storeHooks()
+	}
+}
+
+// storeHooks publishes the registered hooks. It is called with hooksMutex
+// held.
+func storeHooks() {
+	if len(hooksByID) == 0 {
+		hooks.Store(nil)
+		return
+	}
+	snapshot := make([]Hooks, 0, len(hooksByID))
+	for _, h := range hooksByID {
+		snapshot = append(snapshot, h)
+	}
+	hooks.Store(&snapshot)
+}
+
+// notifyCreated identifies p and reports it to the OnCreate hooks.
+func (p *Promise[T]) notifyCreated() {
+	hs := hooks.Load()
+	if hs == nil {
+		return
+	}
+	p.hookID = promises.Add(1)
+	p.site = callerSite()
+	info := p.info(nil)
+	for _, h := range *hs {
+		if h.OnCreate != nil {
+			h.OnCreate(info)
+		}
+	}
+}
+
+// notifySettled reports the outcome of p to the hooks.
+func (p *Promise[T]) notifySettled(status State, err error, panicked bool, raise *error) {
+	hs := hooks.Load()
+	if hs == nil {
+		return
+	}
+	info := p.info(err)
+	info.Duration = time.Since(p.created)
+	for _, h := range *hs {
+		var hook func(PromiseInfo)
+		switch {
+		case status == Fulfilled:
+			hook = h.OnResolve
+		case status == Cancelled:
+			hook = h.OnCancel
+		case panicked:
+			hook = h.OnPanic
+		default:
+			hook = h.OnReject
+		}
+		if hook != nil {
+			guard(raise, func() { hook(info) })
+		}
+	}
+}
+
+func (p *Promise[T]) info(err error) PromiseInfo {
+	return PromiseInfo{ID: p.hookID, Name: p.name, Site: p.site, Created: p.created, Err: err}
+}
+
+var pkgPath = reflect.TypeOf(PromiseInfo{}).PkgPath()
+
+// callerSite returns the file:line of the innermost caller outside this
+// package. Tests of this package count as callers.
+func callerSite() string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPath+".") || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
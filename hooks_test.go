@@ -0,0 +1,51 @@
+package gopromise
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegisterHooks(t *testing.T) {
+	var mutex sync.Mutex
+	events := make(map[string][]PromiseInfo)
+	record := func(event string) func(PromiseInfo) {
+		return func(info PromiseInfo) {
+			if !strings.HasPrefix(info.Name, "hooks-") {
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			events[event] = append(events[event], info)
+		}
+	}
+	unregister := RegisterHooks(Hooks{
+		OnCreate:  record("create"),
+		OnResolve: record("resolve"),
+		OnReject:  record("reject"),
+		OnPanic:   record("panic"),
+		OnCancel:  record("cancel"),
+	})
+
+	New(func(resolve func(int), reject func(error)) { resolve(1) }, WithName("hooks-ok")).Await()
+	New(func(resolve func(int), reject func(error)) { reject(promiseError) }, WithName("hooks-err")).Await()
+	New(func(resolve func(int), reject func(error)) { panic(promiseError) }, WithName("hooks-panic")).Await()
+	p, _, _ := Deferred[int](WithName("hooks-cancel"))
+	p.Cancel(nil)
+	unregister()
+	New(func(resolve func(int), reject func(error)) { resolve(1) }, WithName("hooks-late")).Await()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assertEqual(t, 4, len(events["create"]))
+	assertEqual(t, 1, len(events["resolve"]))
+	assertEqual(t, 1, len(events["reject"]))
+	assertEqual(t, 1, len(events["panic"]))
+	assertEqual(t, 1, len(events["cancel"]))
+
+	created, resolved := events["create"][0], events["resolve"][0]
+	assertEqual(t, "hooks-ok", created.Name)
+	assertEqual(t, created.ID, resolved.ID)
+	assert(t, strings.Contains(created.Site, "hooks_test.go"), "unexpected site "+created.Site)
+	assertEqual(t, promiseError, events["reject"][0].Err)
+}
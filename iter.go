@@ -0,0 +1,40 @@
+//go:build go1.23
+
+package gopromise
+
+import "iter"
+
+// Awaited returns a sequence of the outcomes of promises in the order they
+// settle, so callers can range over results as they become available instead
+// of waiting on All. Breaking out of the loop stops waiting; the promises are
+// left running.
+func Awaited[T any](promises []*Promise[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for s := range AsCompletedSeq(promises...) {
+			if !yield(s.Value, s.Err) {
+				return
+			}
+		}
+	}
+}
+
+// AsCompletedSeq is the sequence form of AsCompleted.
+func AsCompletedSeq[T any](promises ...*Promise[T]) iter.Seq[Settlement[T]] {
+	return func(yield func(Settlement[T]) bool) {
+		for s := range AsCompleted(promises...) {
+			if !yield(s) {
+				return
+			}
+		}
+	}
+}
+
+// MapSeq is like Map but takes its items from a sequence. The sequence is
+// consumed up front, on the calling goroutine.
+func MapSeq[T, R any](items iter.Seq[T], fn func(T) *Promise[R], opts ...Option) *Promise[[]R] {
+	var collected []T
+	for item := range items {
+		collected = append(collected, item)
+	}
+	return Map(collected, fn, opts...)
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package gopromise
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAwaited(t *testing.T) {
+	promises := []*Promise[int]{
+		sleepy(20*time.Millisecond, 1, nil)(),
+		sleepy(0, 0, promiseError)(),
+		sleepy(time.Second, 3, nil)(),
+	}
+
+	var got []int
+	var errs int
+	for val, err := range Awaited(promises) {
+		if err != nil {
+			errs++
+			continue
+		}
+		got = append(got, val)
+		break
+	}
+	assertEqual(t, 1, errs)
+	assertEqual(t, 1, len(got))
+	assertEqual(t, 1, got[0])
+}
+
+func TestMapSeq(t *testing.T) {
+	double := func(v int) *Promise[int] { return Resolve(v * 2) }
+	res, err := MapSeq(slices.Values([]int{1, 2, 3}), double).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, len(res))
+	assertEqual(t, 6, res[2])
+}
+
+func TestAsCompletedSeq(t *testing.T) {
+	for s := range AsCompletedSeq(sleepy(time.Second, 1, nil)(), Resolve(2)) {
+		assertEqual(t, 1, s.Index)
+		break
+	}
+}
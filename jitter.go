@@ -0,0 +1,70 @@
+package gopromise
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Backoff returns how long to wait before the given retry attempt, counting
+// from zero.
+type Backoff func(attempt int) time.Duration
+
+var (
+	jitterMutex sync.Mutex
+	jitterRand  = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// randomDuration returns a uniformly random duration in [0, n].
+func randomDuration(n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	jitterMutex.Lock()
+	defer jitterMutex.Unlock()
+	return time.Duration(jitterRand.Int63n(int64(n) + 1))
+}
+
+// exponential returns base * 2^attempt, capped at max.
+func exponential(base, max time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max || d <= 0 {
+		return max
+	}
+	return d
+}
+
+// FullJitter returns a random delay between zero and base * 2^attempt,
+// capped at max. It spreads retries the most and suits contended resources.
+func FullJitter(base, max time.Duration, attempt int) time.Duration {
+	return randomDuration(exponential(base, max, attempt))
+}
+
+// EqualJitter returns half of base * 2^attempt, capped at max, plus a random
+// delay up to the other half. It never waits less than half the exponential
+// delay.
+func EqualJitter(base, max time.Duration, attempt int) time.Duration {
+	d := exponential(base, max, attempt)
+	return d/2 + randomDuration(d-d/2)
+}
+
+// DecorrelatedJitter returns a random delay between base and three times
+// prev, capped at max, where prev is the delay it returned last time (or base
+// for the first retry).
+func DecorrelatedJitter(base, max, prev time.Duration) time.Duration {
+	upper := prev * 3
+	if upper < base {
+		upper = base
+	}
+	d := base + randomDuration(upper-base)
+	if d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,31 @@
+package gopromise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFullJitter(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		d := FullJitter(10*time.Millisecond, 100*time.Millisecond, attempt)
+		assert(t, d >= 0 && d <= 100*time.Millisecond, "full jitter out of range")
+	}
+	assertEqual(t, time.Duration(0), FullJitter(0, time.Second, 3))
+}
+
+func TestEqualJitter(t *testing.T) {
+	for attempt := 0; attempt < 10; attempt++ {
+		capped := exponential(10*time.Millisecond, 100*time.Millisecond, attempt)
+		d := EqualJitter(10*time.Millisecond, 100*time.Millisecond, attempt)
+		assert(t, d >= capped/2 && d <= capped, "equal jitter out of range")
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	prev := 10 * time.Millisecond
+	for idx := 0; idx < 10; idx++ {
+		d := DecorrelatedJitter(10*time.Millisecond, 200*time.Millisecond, prev)
+		assert(t, d >= 10*time.Millisecond && d <= 200*time.Millisecond, "decorrelated jitter out of range")
+		prev = d
+	}
+}
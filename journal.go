@@ -0,0 +1,121 @@
+package gopromise
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JournalEntry records the outcome of one settled promise.
+type JournalEntry struct {
+	Name     string        `json:"n,omitempty"`
+	Key      string        `json:"k,omitempty"`
+	Duration time.Duration `json:"d"`
+	Status   string        `json:"s"`
+	Error    string        `json:"e,omitempty"`
+}
+
+// JournalSink receives an entry for every promise that settles while it is
+// installed with SetJournal. Record is called on the settling goroutine, so
+// it should be fast and safe for concurrent use.
+type JournalSink interface {
+	Record(entry JournalEntry)
+}
+
+type journalHolder struct {
+	sink JournalSink
+}
+
+var journal atomic.Pointer[journalHolder]
+
+// SetJournal installs sink as the process-wide journal. Passing nil turns
+// journaling off, which is the default.
+func SetJournal(sink JournalSink) {
+	if sink == nil {
+		journal.Store(nil)
+		return
+	}
+	journal.Store(&journalHolder{sink: sink})
+}
+
+// NewJournaled is like New but labels the promise with name and key in the
+// journal, so its entries can later be picked out and replayed. The key
+// usually identifies the input the executor worked on.
+func NewJournaled[T any](name, key string, exec func(resolve func(T), reject func(error))) *Promise[T] {
+	if exec == nil {
+		panic("executor cannot be nil")
+	}
+	o := buildOptions(nil)
+	p := newNamedPromise[T](name)
+	p.key = key
+	p.start(exec, o)
+	p.enforce(o)
+	return p
+}
+
+type jsonJournal struct {
+	mutex sync.Mutex
+	enc   *json.Encoder
+}
+
+// JSONJournal returns a JournalSink that writes one compact JSON object per
+// entry to w. It is safe for concurrent use.
+func JSONJournal(w io.Writer) JournalSink {
+	return &jsonJournal{enc: json.NewEncoder(w)}
+}
+
+func (j *jsonJournal) Record(entry JournalEntry) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	_ = j.enc.Encode(entry)
+}
+
+// ReadJournal decodes the entries written by a JSONJournal.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Replay re-drives the key of every entry recorded under name through drive
+// and compares the outcome with the recording. It returns an error describing
+// every entry whose status or rejection reason differs; a cancelled promise
+// does not match a recorded rejection, nor the other way round.
+func Replay[T any](entries []JournalEntry, name string, drive func(key string) *Promise[T]) error {
+	var mismatches []string
+	for _, entry := range entries {
+		if entry.Name != name {
+			continue
+		}
+		p := drive(entry.Key)
+		_, err := p.Await()
+		status, reason := p.State().String(), ""
+		if err != nil {
+			reason = err.Error()
+		}
+		if status != entry.Status || reason != entry.Error {
+			mismatches = append(mismatches, fmt.Sprintf("%s[%s]: recorded %s %q, replayed %s %q",
+				name, entry.Key, entry.Status, entry.Error, status, reason))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("replay mismatch: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
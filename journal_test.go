@@ -0,0 +1,68 @@
+package gopromise
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func double(key string) *Promise[int] {
+	return New(func(resolve func(int), reject func(error)) {
+		n, err := strconv.Atoi(key)
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(n * 2)
+	})
+}
+
+func TestJournal_RecordAndReplay(t *testing.T) {
+	var buf bytes.Buffer
+	SetJournal(JSONJournal(&buf))
+	for _, key := range []string{"1", "2", "x"} {
+		key := key
+		NewJournaled("double", key, func(resolve func(int), reject func(error)) {
+			val, err := double(key).Await()
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(val)
+		}).Await()
+	}
+	SetJournal(nil)
+
+	entries, err := ReadJournal(&buf)
+	assertNil(t, err)
+
+	var recorded []JournalEntry
+	for _, entry := range entries {
+		if entry.Name == "double" {
+			recorded = append(recorded, entry)
+		}
+	}
+	assertEqual(t, 3, len(recorded))
+	assertEqual(t, "fulfilled", recorded[0].Status)
+	assertEqual(t, "rejected", recorded[2].Status)
+
+	assertNil(t, Replay(entries, "double", double))
+
+	err = Replay(entries, "double", func(key string) *Promise[int] {
+		return Resolve(0)
+	})
+	assertErr(t, err)
+}
+
+func TestReplay_Cancelled(t *testing.T) {
+	cancelled := func(string) *Promise[int] {
+		p, _, _ := Deferred[int]()
+		p.Cancel(nil)
+		return p
+	}
+	entries := []JournalEntry{{Name: "op", Key: "1", Status: "cancelled", Error: ErrCancelled.Error()}}
+	assertNil(t, Replay(entries, "op", cancelled))
+
+	entries[0].Status = "rejected"
+	assertErr(t, Replay(entries, "op", cancelled))
+}
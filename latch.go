@@ -0,0 +1,87 @@
+package gopromise
+
+import "sync"
+
+// Latch lets goroutines wait until a number of events have happened. Once
+// CountDown has been called n times, every past and future Wait fulfills.
+type Latch struct {
+	mutex sync.Mutex
+	count int
+	done  *Promise[struct{}]
+}
+
+// NewLatch returns a Latch that opens after n calls to CountDown. A Latch
+// with n <= 0 is open from the start.
+func NewLatch(n int) *Latch {
+	l := &Latch{count: n, done: newPromise[struct{}]()}
+	if n <= 0 {
+		l.done.resolve(struct{}{})
+	}
+	return l
+}
+
+// CountDown records one event. Calls beyond the count have no effect.
+func (l *Latch) CountDown() {
+	l.mutex.Lock()
+	if l.count <= 0 {
+		l.mutex.Unlock()
+		return
+	}
+	l.count--
+	open := l.count == 0
+	l.mutex.Unlock()
+	if open {
+		l.done.resolve(struct{}{})
+	}
+}
+
+// Count returns the number of events still to happen.
+func (l *Latch) Count() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.count < 0 {
+		return 0
+	}
+	return l.count
+}
+
+// Wait returns a Promise fulfilled once the latch is open.
+func (l *Latch) Wait() *Promise[struct{}] {
+	return follow(l.done)
+}
+
+// Barrier lets a fixed number of parties wait for each other. Once n parties
+// have called Await, all of their promises fulfill and the barrier resets
+// for the next round.
+type Barrier struct {
+	mutex   sync.Mutex
+	parties int
+	arrived int
+	round   *Promise[struct{}]
+}
+
+// NewBarrier returns a Barrier for n parties.
+func NewBarrier(n int) *Barrier {
+	if n <= 0 {
+		panic("parties must be positive")
+	}
+	return &Barrier{parties: n, round: newPromise[struct{}]()}
+}
+
+// Await records the arrival of a party and returns a Promise fulfilled once
+// all parties of the current round have arrived.
+func (b *Barrier) Await() *Promise[struct{}] {
+	b.mutex.Lock()
+	round := b.round
+	b.arrived++
+	full := b.arrived == b.parties
+	if full {
+		b.arrived = 0
+		b.round = newPromise[struct{}]()
+	}
+	b.mutex.Unlock()
+	if full {
+		round.resolve(struct{}{})
+	}
+	return follow(round)
+}
@@ -0,0 +1,37 @@
+package gopromise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatch(t *testing.T) {
+	l := NewLatch(2)
+	waiting := l.Wait()
+	l.CountDown()
+	_, err := waiting.AwaitTimeout(10 * time.Millisecond)
+	assertEqual(t, ErrAwaitTimeout, err)
+	assertEqual(t, 1, l.Count())
+
+	l.CountDown()
+	l.CountDown()
+	_, err = waiting.Await()
+	assertNotErr(t, err)
+	_, err = l.Wait().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 0, l.Count())
+}
+
+func TestBarrier(t *testing.T) {
+	b := NewBarrier(3)
+	for round := 0; round < 2; round++ {
+		first := b.Await()
+		second := b.Await()
+		_, err := first.AwaitTimeout(10 * time.Millisecond)
+		assertEqual(t, ErrAwaitTimeout, err)
+
+		last := b.Await()
+		_, err = All(first, second, last).Await()
+		assertNotErr(t, err)
+	}
+}
@@ -0,0 +1,36 @@
+package gopromise
+
+import "sync"
+
+// lazyStart holds the deferred start of a promise created by NewLazy.
+type lazyStart struct {
+	once  sync.Once
+	start func()
+}
+
+// NewLazy is like New but runs exec only once the promise is first used:
+// awaited in any way, passed to Then or another combinator, or handed to
+// AsContext. The timeout set by WithTimeout counts from that moment. A lazy
+// promise cancelled before its first use never runs exec.
+func NewLazy[T any](exec func(resolve func(T), reject func(error)), opts ...Option) *Promise[T] {
+	if exec == nil {
+		panic("executor cannot be nil")
+	}
+	o := buildOptions(opts)
+	p := newNamedPromise[T](o.name)
+	p.lazy = &lazyStart{start: func() {
+		if p.isSettled() {
+			return
+		}
+		p.start(exec, o)
+		p.enforce(o)
+	}}
+	return p
+}
+
+// wake starts the executor of a lazy promise the first time it is used.
+func (p *Promise[T]) wake() {
+	if p.lazy != nil {
+		p.lazy.once.Do(p.lazy.start)
+	}
+}
@@ -0,0 +1,51 @@
+package gopromise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewLazy(t *testing.T) {
+	var runs int32
+	p := NewLazy(func(resolve func(int), reject func(error)) {
+		atomic.AddInt32(&runs, 1)
+		resolve(42)
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	assertEqual(t, int32(0), atomic.LoadInt32(&runs), "executor should not run before first use")
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 42, res)
+	p.Await()
+	assertEqual(t, int32(1), atomic.LoadInt32(&runs))
+}
+
+func TestNewLazy_Chained(t *testing.T) {
+	p := NewLazy(func(resolve func(int), reject func(error)) { resolve(1) })
+	res, err := Then(p, func(v int) int { return v + 1 }).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+
+	select {
+	case <-NewLazy(func(resolve func(int), reject func(error)) { resolve(1) }).Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done should start a lazy promise")
+	}
+}
+
+func TestNewLazy_CancelledBeforeUse(t *testing.T) {
+	ran := false
+	p := NewLazy(func(resolve func(int), reject func(error)) {
+		ran = true
+		resolve(1)
+	})
+	p.Cancel(nil)
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+	assert(t, !ran, "a cancelled lazy promise should never run")
+}
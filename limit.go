@@ -0,0 +1,46 @@
+package gopromise
+
+import "sync/atomic"
+
+type limiter struct {
+	slots chan struct{}
+}
+
+var globalLimiter atomic.Pointer[limiter]
+
+// SetConcurrencyLimit caps the number of executors passed to New that may run
+// at the same time across the whole process, whichever code path or
+// combinator created them. Executors over the limit wait for a free slot
+// before they start. A limit of zero or less removes the cap, which is the
+// default.
+//
+// Executors that block on other promises still hold their slot while they
+// wait, so a limit lower than the depth of such nesting can deadlock.
+func SetConcurrencyLimit(n int) {
+	if n <= 0 {
+		globalLimiter.Store(nil)
+		return
+	}
+	globalLimiter.Store(&limiter{slots: make(chan struct{}, n)})
+}
+
+// ConcurrencyLimit returns the current process-wide executor limit, or zero
+// when no limit is set.
+func ConcurrencyLimit() int {
+	l := globalLimiter.Load()
+	if l == nil {
+		return 0
+	}
+	return cap(l.slots)
+}
+
+// acquireSlot blocks until the global limiter admits another executor and
+// returns the function that gives the slot back.
+func acquireSlot() func() {
+	l := globalLimiter.Load()
+	if l == nil {
+		return func() {}
+	}
+	l.slots <- struct{}{}
+	return func() { <-l.slots }
+}
@@ -0,0 +1,57 @@
+package gopromise
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetConcurrencyLimit(t *testing.T) {
+	SetConcurrencyLimit(2)
+	defer SetConcurrencyLimit(0)
+	assertEqual(t, 2, ConcurrencyLimit())
+
+	var running, peak int32
+	promises := make([]*Promise[int], 6)
+	for idx := range promises {
+		idx := idx
+		promises[idx] = New(func(resolve func(int), reject func(error)) {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			resolve(idx)
+		})
+	}
+
+	res, err := All(promises...).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 6, len(res))
+	assert(t, atomic.LoadInt32(&peak) <= 2, "more than 2 executors ran at once")
+}
+
+func TestSetConcurrencyLimit_CombinatorsNotCounted(t *testing.T) {
+	SetConcurrencyLimit(1)
+	defer SetConcurrencyLimit(0)
+
+	p := New(func(resolve func(int), reject func(error)) {
+		resolve(1)
+	})
+	for idx := 0; idx < 5; idx++ {
+		p = Then(p, func(v int) int { return v + 1 })
+	}
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 6, res)
+}
+
+func TestSetConcurrencyLimit_Disabled(t *testing.T) {
+	SetConcurrencyLimit(0)
+	assertEqual(t, 0, ConcurrencyLimit())
+}
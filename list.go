@@ -0,0 +1,39 @@
+package gopromise
+
+// PromiseList is a set of promises of the same type, built up incrementally
+// and then combined through its methods, e.g.
+//
+//	list.Filter(notCached).All().Await()
+type PromiseList[T any] []*Promise[T]
+
+// Append returns the list with promises added at the end.
+func (l PromiseList[T]) Append(promises ...*Promise[T]) PromiseList[T] {
+	return append(l, promises...)
+}
+
+// Filter returns a new list of the promises of l that satisfy pred.
+func (l PromiseList[T]) Filter(pred func(p *Promise[T]) bool) PromiseList[T] {
+	var filtered PromiseList[T]
+	for _, p := range l {
+		if pred(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// All is All over the promises of l.
+func (l PromiseList[T]) All(opts ...Option) *Promise[[]T] {
+	return AllWith(l, opts...)
+}
+
+// Race is Race over the promises of l.
+func (l PromiseList[T]) Race(opts ...Option) *Promise[T] {
+	return RaceWith(l, opts...)
+}
+
+// AnySettled returns a Promise that fulfills with the settlement of the first
+// promise of l to settle, like RaceSettled.
+func (l PromiseList[T]) AnySettled() *Promise[Settlement[T]] {
+	return RaceSettled(l...)
+}
@@ -0,0 +1,28 @@
+package gopromise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPromiseList(t *testing.T) {
+	var list PromiseList[int]
+	for i := 1; i <= 4; i++ {
+		list = list.Append(sleepy(time.Duration(i)*20*time.Millisecond, i, nil)())
+	}
+	list = list.Append(Reject[int](promiseError))
+
+	first, err := list.AnySettled().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 4, first.Index)
+
+	_, err = list.Race().Await()
+	assertEqual(t, promiseError, err)
+
+	_, err = list.All().Await()
+	assertErr(t, err)
+
+	res, err := list.Filter(func(p *Promise[int]) bool { return p != list[4] }).All().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 4, len(res))
+}
@@ -0,0 +1,103 @@
+package gopromise
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// histogramBounds are the upper bounds of every histogram bucket. Durations
+// above the last bound land in an extra overflow bucket.
+var histogramBounds = []time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// Histogram is a point-in-time snapshot of observed durations. Counts has one
+// entry per bound plus a final overflow bucket.
+type Histogram struct {
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// Metrics is a snapshot of the package-wide metrics.
+//
+// AwaitDuration measures how long callers of Await stay blocked, while
+// SettleDuration measures how long promises take to settle after creation. A
+// slow producer shows up in SettleDuration; a consumer that awaits too late or
+// on the wrong goroutine shows up in AwaitDuration only.
+type Metrics struct {
+	AwaitDuration  Histogram
+	SettleDuration Histogram
+}
+
+type histogram struct {
+	counts []atomic.Uint64
+	count  atomic.Uint64
+	sum    atomic.Int64
+}
+
+func newHistogram() *histogram {
+	return &histogram{counts: make([]atomic.Uint64, len(histogramBounds)+1)}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	idx := len(histogramBounds)
+	for i, bound := range histogramBounds {
+		if d <= bound {
+			idx = i
+			break
+		}
+	}
+	h.counts[idx].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *histogram) snapshot() Histogram {
+	s := Histogram{
+		Bounds: append([]time.Duration(nil), histogramBounds...),
+		Counts: make([]uint64, len(h.counts)),
+		Count:  h.count.Load(),
+		Sum:    time.Duration(h.sum.Load()),
+	}
+	for i := range h.counts {
+		s.Counts[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+var (
+	metricsEnabled atomic.Bool
+	awaitHist      atomic.Pointer[histogram]
+	settleHist     atomic.Pointer[histogram]
+)
+
+func init() {
+	ResetMetrics()
+}
+
+// EnableMetrics turns collection of package-wide metrics on or off. Metrics
+// are off by default.
+func EnableMetrics(enabled bool) {
+	metricsEnabled.Store(enabled)
+}
+
+// ReadMetrics returns a snapshot of the metrics collected so far.
+func ReadMetrics() Metrics {
+	return Metrics{
+		AwaitDuration:  awaitHist.Load().snapshot(),
+		SettleDuration: settleHist.Load().snapshot(),
+	}
+}
+
+// ResetMetrics discards every metric collected so far.
+func ResetMetrics() {
+	awaitHist.Store(newHistogram())
+	settleHist.Store(newHistogram())
+}
@@ -0,0 +1,35 @@
+package gopromise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetrics_AwaitDuration(t *testing.T) {
+	EnableMetrics(true)
+	ResetMetrics()
+	defer EnableMetrics(false)
+
+	p := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(20 * time.Millisecond)
+		resolve(1)
+	})
+	p.Await()
+	p.Await()
+
+	m := ReadMetrics()
+	assertEqual(t, uint64(2), m.AwaitDuration.Count)
+	assert(t, m.SettleDuration.Count >= 1, "settlement not recorded")
+	assertEqual(t, len(m.AwaitDuration.Bounds)+1, len(m.AwaitDuration.Counts))
+	assert(t, m.SettleDuration.Sum >= 20*time.Millisecond, "settle duration too short")
+}
+
+func TestMetrics_Disabled(t *testing.T) {
+	EnableMetrics(false)
+	ResetMetrics()
+
+	Resolve(1).Await()
+
+	m := ReadMetrics()
+	assertEqual(t, uint64(0), m.AwaitDuration.Count)
+}
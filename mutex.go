@@ -0,0 +1,48 @@
+package gopromise
+
+// Unlocker releases a lock acquired from an AsyncMutex. Only its first call
+// to Unlock has an effect.
+type Unlocker interface {
+	Unlock()
+}
+
+type unlockFunc func()
+
+func (f unlockFunc) Unlock() {
+	f()
+}
+
+// AsyncMutex is a mutual exclusion lock acquired through a promise, so that
+// waiting for it can be combined with other promises, e.g. given up with
+// AwaitTimeout or raced against a cancellation. Waiters acquire the lock in
+// the order they asked for it. The zero value is not usable; call
+// NewAsyncMutex.
+type AsyncMutex struct {
+	sem *Semaphore
+}
+
+// NewAsyncMutex returns an unlocked AsyncMutex.
+func NewAsyncMutex() *AsyncMutex {
+	return &AsyncMutex{sem: NewSemaphore(1)}
+}
+
+// Lock returns a Promise fulfilled with an Unlocker once m is locked:
+//
+//	u, err := m.Lock().Await()
+//	if err != nil {
+//		return err
+//	}
+//	defer u.Unlock()
+//
+// Cancelling the promise before it fulfills withdraws the request.
+func (m *AsyncMutex) Lock() *Promise[Unlocker] {
+	return acquireAsync(m.sem, 1, func(release func()) Unlocker { return unlockFunc(release) })
+}
+
+// TryLock locks m if it is free and reports whether it did.
+func (m *AsyncMutex) TryLock() (Unlocker, bool) {
+	if !m.sem.TryAcquire(1) {
+		return nil, false
+	}
+	return unlockFunc(m.sem.releaser(1)), true
+}
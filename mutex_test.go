@@ -0,0 +1,45 @@
+package gopromise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAsyncMutex(t *testing.T) {
+	m := NewAsyncMutex()
+	u, err := m.Lock().Await()
+	assertNotErr(t, err)
+
+	_, ok := m.TryLock()
+	assert(t, !ok, "the mutex should be locked")
+	late := m.Lock()
+	_, err = late.AwaitTimeout(10 * time.Millisecond)
+	assertEqual(t, ErrAwaitTimeout, err)
+	late.Cancel(nil)
+
+	next := m.Lock()
+	u.Unlock()
+	u.Unlock()
+	u2, err := next.Await()
+	assertNotErr(t, err)
+	u2.Unlock()
+
+	u3, ok := m.TryLock()
+	assert(t, ok, "the mutex should be free")
+	u3.Unlock()
+}
+
+func TestAsyncMutex_CancelledWaiter(t *testing.T) {
+	m := NewAsyncMutex()
+	u, _ := m.Lock().Await()
+	waiter := m.Lock()
+	waiter.Cancel(nil)
+	_, err := waiter.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+	u.Unlock()
+
+	u, err = m.Lock().AwaitTimeout(time.Second)
+	assertNotErr(t, err, "a cancelled waiter should not keep the lock")
+	u.Unlock()
+}
@@ -0,0 +1,182 @@
+package gopromise
+
+import (
+	"context"
+	"time"
+)
+
+// Option configures a promise or a combinator. Functions that accept options
+// document which ones they honour; the others are ignored.
+type Option func(*options)
+
+type options struct {
+	deterministic bool
+	timeout       time.Duration
+	ctx           context.Context
+	concurrency   int
+	collectAll    bool
+	itemTimeout   time.Duration
+	scheduler     Scheduler
+	priority      Priority
+	name          string
+	panicHandler  func(recovered any)
+	panicPolicy   PanicPolicy
+	inline        bool
+	settleLater   bool
+	strict        bool
+	misuseHandler func(err error)
+}
+
+// buildOptions applies opts on top of the package-wide defaults. Without
+// opts, it returns the options shared by every such call, which must not be
+// modified.
+func buildOptions(opts []Option) *options {
+	s := currentSettings()
+	if len(opts) == 0 {
+		return &s.options
+	}
+	o := new(options)
+	*o = s.options
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// defaultOptions returns the options that c amounts to.
+func defaultOptions(c Config) options {
+	return options{
+		timeout:       c.Timeout,
+		scheduler:     c.Scheduler,
+		panicHandler:  c.PanicHandler,
+		panicPolicy:   c.PanicPolicy,
+		strict:        c.Strict,
+		misuseHandler: c.MisuseHandler,
+	}
+}
+
+// WithDeterministic makes RaceWith settle with the lowest-indexed input when
+// several inputs have already settled by the time it is called, instead of
+// whichever one the scheduler happens to observe first.
+func WithDeterministic() Option {
+	return func(o *options) {
+		o.deterministic = true
+	}
+}
+
+// WithTimeout rejects the promise with a *TimeoutError when it has not settled
+// within d. The executor of a promise created by NewWithContext sees its
+// context cancelled. WithTimeout(0) overrides a default timeout set through
+// SetDefaults.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithContext cancels the promise with the cause of ctx when ctx is done
+// before the promise settles.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithName names the promise, so that it can be told apart in diagnostics:
+// its String, its TimeoutError, the journal and the pending promises listed
+// by a DrainError.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithInline makes New run its executor right away on the calling goroutine
+// instead of handing it to the scheduler, which saves a goroutine when the
+// executor does trivial, non-blocking work such as wrapping a cached value.
+// Continuations of the promise are scheduled as usual.
+func WithInline() Option {
+	return func(o *options) {
+		o.inline = true
+	}
+}
+
+// WithSettleLater lets the executor return before the promise has settled,
+// leaving resolve or reject to be called later, for instance from a callback.
+// Without it, a promise whose executor returns without settling it rejects
+// with ErrExecutorReturnedWithoutSettling.
+func WithSettleLater() Option {
+	return func(o *options) {
+		o.settleLater = true
+	}
+}
+
+// WithConcurrency makes Map run at most n calls at a time. Zero or a negative
+// n means no limit.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithFailFast controls whether AllWith and Map reject as soon as one input
+// rejects, which is the default, or wait for every input and reject with an
+// *AggregateError holding the rejection reasons.
+func WithFailFast(failFast bool) Option {
+	return func(o *options) {
+		o.collectAll = !failFast
+	}
+}
+
+// WithItemTimeout makes AllWith and Map treat an input that has not settled
+// within d as rejected with an error wrapping a *TimeoutError that names its
+// index, so one slow input cannot hold up the whole result. The input itself
+// is left running.
+func WithItemTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.itemTimeout = d
+	}
+}
+
+// awaitItem waits for the input p, giving up after d when d is positive.
+func awaitItem[T any](p *Promise[T], d time.Duration) (T, error) {
+	if d <= 0 {
+		return p.await()
+	}
+	p.wake()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+		return p.await()
+	case <-timer.C:
+		var zero T
+		return zero, &TimeoutError{After: d, PromiseName: p.name}
+	}
+}
+
+// enforce settles p according to the deadlines in o, should they pass first.
+func (p *Promise[T]) enforce(o *options) {
+	if o.timeout <= 0 && o.ctx == nil {
+		return
+	}
+	go func() {
+		var expired <-chan time.Time
+		if o.timeout > 0 {
+			timer := time.NewTimer(o.timeout)
+			defer timer.Stop()
+			expired = timer.C
+		}
+		var ctxDone <-chan struct{}
+		if o.ctx != nil {
+			ctxDone = o.ctx.Done()
+		}
+		select {
+		case <-p.done:
+		case <-expired:
+			p.reject(&TimeoutError{After: o.timeout, PromiseName: p.name})
+		case <-ctxDone:
+			p.Cancel(context.Cause(o.ctx))
+		}
+	}()
+}
@@ -0,0 +1,173 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew_WithTimeout(t *testing.T) {
+	p := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(time.Second)
+		resolve(1)
+	}, WithTimeout(10*time.Millisecond))
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrTimeout), "expected a timeout")
+
+	fast := New(func(resolve func(int), reject func(error)) {
+		resolve(2)
+	}, WithTimeout(time.Second))
+	res, err := fast.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+}
+
+func TestNew_WithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	p := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		<-ctx.Done()
+		close(stopped)
+	}, WithContext(ctx))
+
+	cancel()
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+	assert(t, errors.Is(err, context.Canceled))
+	<-stopped
+}
+
+func TestNewWithContext_WithTimeout(t *testing.T) {
+	stopped := make(chan struct{})
+	p := NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+		<-ctx.Done()
+		close(stopped)
+	}, WithTimeout(10*time.Millisecond))
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrTimeout), "expected a timeout")
+	<-stopped
+}
+
+func TestAllWith_CollectAll(t *testing.T) {
+	err1 := errors.New("Err 1")
+	p := AllWith([]*Promise[int]{
+		sleepy(10*time.Millisecond, 0, err1)(),
+		Resolve(2),
+		Reject[int](promiseError),
+	}, WithFailFast(false))
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, err1) && errors.Is(err, promiseError), "expected both rejection reasons")
+	var agg *AggregateError
+	assert(t, errors.As(err, &agg), "expected an *AggregateError")
+	assertEqual(t, 2, len(agg.Errors))
+	assertEqual(t, IndexedError{Index: 2, Err: promiseError}, agg.Errors[1])
+}
+
+func TestCombinators_WithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	slow := sleepy(time.Second, 1, nil)
+	all := AllWith([]*Promise[int]{slow()}, WithContext(ctx))
+	race := RaceWith([]*Promise[int]{slow()}, WithContext(ctx))
+	anyP := AnyWith([]*Promise[int]{slow()}, WithContext(ctx))
+	mapped := Map([]int{1}, func(int) *Promise[int] { return slow() }, WithContext(ctx))
+	cancel()
+
+	_, err := all.Await()
+	assert(t, errors.Is(err, ErrCancelled), "AllWith should be cancelled")
+	_, err = race.Await()
+	assert(t, errors.Is(err, ErrCancelled), "RaceWith should be cancelled")
+	_, err = anyP.Await()
+	assert(t, errors.Is(err, ErrCancelled), "AnyWith should be cancelled")
+	_, err = mapped.Await()
+	assert(t, errors.Is(err, ErrCancelled), "Map should be cancelled")
+}
+
+func TestAllWith_ItemTimeout(t *testing.T) {
+	start := time.Now()
+	p := AllWith([]*Promise[int]{
+		Resolve(1),
+		sleepy(time.Second, 2, nil)(),
+		Resolve(3),
+	}, WithItemTimeout(10*time.Millisecond))
+
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrTimeout), "expected a timeout")
+	assertEqual(t, "promise 1: promise timed out after 10ms", err.Error())
+	assert(t, time.Since(start) < 500*time.Millisecond, "the slow item should not hold up All")
+}
+
+func TestMap_ItemTimeout(t *testing.T) {
+	slowEven := func(v int) *Promise[int] {
+		if v%2 == 0 {
+			return sleepy(time.Second, v, nil)()
+		}
+		return Resolve(v)
+	}
+
+	_, err := Map([]int{1, 2, 3, 4}, slowEven, WithItemTimeout(10*time.Millisecond), WithFailFast(false)).Await()
+	assertEqual(t, "2 errors occurred:\n\tpromise 1: promise timed out after 10ms\n\tpromise 3: promise timed out after 10ms", err.Error())
+}
+
+func TestTimeoutError(t *testing.T) {
+	p := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(time.Second)
+		resolve(1)
+	}, WithTimeout(10*time.Millisecond))
+
+	_, err := p.Await()
+	var timeoutErr *TimeoutError
+	assert(t, errors.As(err, &timeoutErr), "expected a *TimeoutError")
+	assertEqual(t, 10*time.Millisecond, timeoutErr.After)
+	assert(t, !errors.Is(err, ErrCancelled))
+
+	assert(t, errors.Is(ErrAwaitTimeout, ErrTimeout), "ErrAwaitTimeout should match ErrTimeout")
+}
+
+func TestWithName(t *testing.T) {
+	p := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(time.Second)
+		resolve(1)
+	}, WithName("fetch-user"), WithTimeout(10*time.Millisecond))
+
+	_, err := p.Await()
+	var timeoutErr *TimeoutError
+	assert(t, errors.As(err, &timeoutErr), "expected a *TimeoutError")
+	assertEqual(t, "fetch-user", timeoutErr.PromiseName)
+	assertEqual(t, `promise "fetch-user" timed out after 10ms`, err.Error())
+	assert(t, strings.HasSuffix(p.String(), ", name=fetch-user}"), p.String())
+
+	all := AllWith([]*Promise[int]{Resolve(1)}, WithName("batch"))
+	all.Await()
+	assert(t, strings.HasSuffix(all.String(), ", name=batch}"), all.String())
+}
+
+func TestWithInline(t *testing.T) {
+	ran := false
+	p := New(func(resolve func(int), reject func(error)) {
+		ran = true
+		resolve(1)
+	}, WithInline())
+	assert(t, ran, "inline executor should run before New returns")
+	assert(t, p.IsFulfilled())
+
+	val := p.Then(func(val int) int { return val + 1 }).MustAwait()
+	assertEqual(t, 2, val)
+}
+
+func TestWithSettleLater(t *testing.T) {
+	_, err := New(func(resolve func(int), reject func(error)) {}).Await()
+	assertEqual(t, ErrExecutorReturnedWithoutSettling, err)
+
+	p := New(func(resolve func(int), reject func(error)) {
+		time.AfterFunc(10*time.Millisecond, func() { resolve(1) })
+	}, WithSettleLater())
+	val, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, val)
+}
@@ -0,0 +1,183 @@
+package gopromise
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPipelineClosed is the rejection reason of items pushed into a Pipeline
+// after Close.
+var ErrPipelineClosed = errors.New("pipeline closed")
+
+// Pipeline is a chain of stages that transform items from In to Out. Each
+// stage runs its transform on its own set of goroutines and buffers the
+// items waiting for it, so slow stages apply backpressure to the ones before
+// them.
+//
+// A Pipeline is built with NewPipeline and AddStage, then fed with Push. It
+// starts on the first Push; adding stages afterward is not allowed.
+type Pipeline[In, Out any] struct {
+	core *pipelineCore
+}
+
+type pipelineCore struct {
+	mutex   sync.RWMutex
+	stages  []*pipelineStage
+	once    sync.Once
+	started bool
+	closed  bool
+}
+
+type pipelineStage struct {
+	fn          func(any) (any, error)
+	concurrency int
+	in          chan pipelineItem
+	wg          sync.WaitGroup
+}
+
+type pipelineItem struct {
+	val     any
+	resolve func(any)
+	reject  func(error)
+}
+
+// NewPipeline returns a Pipeline with a single stage that applies fn to each
+// item on up to concurrency goroutines, buffering up to buffer items.
+func NewPipeline[In, Out any](fn func(In) (Out, error), concurrency, buffer int) *Pipeline[In, Out] {
+	core := &pipelineCore{}
+	core.add(erase(fn), concurrency, buffer)
+	return &Pipeline[In, Out]{core: core}
+}
+
+// AddStage returns p extended with a stage that applies fn to the output of
+// the last stage of p, on up to concurrency goroutines, buffering up to
+// buffer items. The returned Pipeline shares its stages with p, which must
+// not be used afterward.
+func AddStage[In, Mid, Out any](p *Pipeline[In, Mid], fn func(Mid) (Out, error), concurrency, buffer int) *Pipeline[In, Out] {
+	p.core.add(erase(fn), concurrency, buffer)
+	return &Pipeline[In, Out]{core: p.core}
+}
+
+// Push feeds item into p and returns a Promise of its output from the last
+// stage. An error or panic in any stage rejects the promise and drops the
+// item from the rest of the pipeline. Push blocks while the buffer of the
+// first stage is full.
+func (p *Pipeline[In, Out]) Push(item In) *Promise[Out] {
+	c := p.core
+	c.once.Do(c.start)
+	out := newPromise[Out]()
+
+	c.mutex.RLock()
+	if c.closed {
+		c.mutex.RUnlock()
+		out.reject(ErrPipelineClosed)
+		return out
+	}
+	defer c.mutex.RUnlock()
+	c.stages[0].in <- pipelineItem{
+		val: item,
+		resolve: func(val any) {
+			// A nil interface holds no Out; the assertion yields the zero value.
+			v, _ := val.(Out)
+			out.resolve(v)
+		},
+		reject: out.reject,
+	}
+	return out
+}
+
+// Close stops p from accepting items and lets the items already pushed run
+// through the remaining stages.
+func (p *Pipeline[In, Out]) Close() {
+	c := p.core
+	c.once.Do(c.start)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.stages[0].in)
+	}
+}
+
+func (c *pipelineCore) add(fn func(any) (any, error), concurrency, buffer int) {
+	if concurrency <= 0 {
+		panic("stage concurrency must be positive")
+	}
+	if buffer < 0 {
+		buffer = 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.started {
+		panic("cannot add a stage to a running pipeline")
+	}
+	c.stages = append(c.stages, &pipelineStage{
+		fn:          fn,
+		concurrency: concurrency,
+		in:          make(chan pipelineItem, buffer),
+	})
+}
+
+func (c *pipelineCore) start() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.started = true
+	for idx, stage := range c.stages {
+		var next *pipelineStage
+		if idx+1 < len(c.stages) {
+			next = c.stages[idx+1]
+		}
+		stage.wg.Add(stage.concurrency)
+		for w := 0; w < stage.concurrency; w++ {
+			go stage.work(next)
+		}
+		if next != nil {
+			// Closing a stage's input once the stage before it is done lets
+			// Close ripple down the pipeline.
+			go func(stage *pipelineStage) {
+				stage.wg.Wait()
+				close(next.in)
+			}(stage)
+		}
+	}
+}
+
+func (s *pipelineStage) work(next *pipelineStage) {
+	defer s.wg.Done()
+	for item := range s.in {
+		val, err := s.apply(item.val)
+		switch {
+		case err != nil:
+			item.reject(err)
+		case next != nil:
+			item.val = val
+			next.in <- item
+		default:
+			item.resolve(val)
+		}
+	}
+}
+
+// apply runs the stage's transform, turning a panic into an error.
+func (s *pipelineStage) apply(val any) (out any, err error) {
+	returned := false
+	defer func() {
+		if !returned {
+			err = panicError(recover(), nil)
+		}
+	}()
+	out, err = s.fn(val)
+	returned = true
+	return out, err
+}
+
+// erase adapts a typed stage transform to the untyped form stages share.
+func erase[In, Out any](fn func(In) (Out, error)) func(any) (any, error) {
+	if fn == nil {
+		panic("stage function cannot be nil")
+	}
+	return func(val any) (any, error) {
+		in, _ := val.(In)
+		return fn(in)
+	}
+}
@@ -0,0 +1,60 @@
+package gopromise
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	parse := NewPipeline(func(s string) (int, error) { return strconv.Atoi(s) }, 2, 4)
+	double := AddStage(parse, func(v int) (int, error) { return v * 2, nil }, 3, 4)
+	format := AddStage(double, func(v int) (string, error) { return "#" + strconv.Itoa(v), nil }, 1, 0)
+	defer format.Close()
+
+	var promises []*Promise[string]
+	for _, in := range []string{"1", "2", "x", "4"} {
+		promises = append(promises, format.Push(in))
+	}
+
+	res, err := promises[0].Await()
+	assertNotErr(t, err)
+	assertEqual(t, "#2", res)
+	res, err = promises[3].Await()
+	assertNotErr(t, err)
+	assertEqual(t, "#8", res)
+	_, err = promises[2].Await()
+	assertErr(t, err)
+}
+
+func TestPipeline_Close(t *testing.T) {
+	p := NewPipeline(func(v int) (int, error) {
+		if v < 0 {
+			panic("negative")
+		}
+		return v + 1, nil
+	}, 1, 1)
+
+	pushed := p.Push(1)
+	_, err := p.Push(-1).Await()
+	assertEqual(t, "negative", err.Error())
+	p.Close()
+
+	res, err := pushed.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+	_, err = p.Push(2).Await()
+	assertEqual(t, ErrPipelineClosed, err)
+}
+
+func TestPipeline_PanicHandler(t *testing.T) {
+	var recovered atomic.Value
+	SetDefaults(Config{PanicHandler: func(r any) { recovered.Store(r) }})
+	defer SetDefaults(Config{})
+
+	p := NewPipeline(func(int) (int, error) { panic("boom") }, 1, 0)
+	defer p.Close()
+	_, err := p.Push(1).Await()
+	assertEqual(t, "boom", err.Error())
+	assertEqual(t, "boom", recovered.Load())
+}
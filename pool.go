@@ -0,0 +1,47 @@
+package gopromise
+
+import "errors"
+
+// ErrPoolClosed is the rejection reason of tasks submitted to a Pool after
+// Close, and of the tasks still queued when Close was called.
+var ErrPoolClosed = errors.New("pool closed")
+
+// Pool runs submitted tasks on a bounded number of goroutines and hands
+// each submitter a promise of its task's result.
+type Pool struct {
+	work *workQueue
+}
+
+// PoolStats counts the tasks of a Pool.
+type PoolStats struct {
+	// Queued is the number of tasks waiting to start.
+	Queued int
+	// Running is the number of tasks being run.
+	Running int
+	// Completed is the number of tasks that have run so far.
+	Completed int
+}
+
+// NewPool returns a Pool that runs up to workers tasks at a time.
+func NewPool(workers int) *Pool {
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	return &Pool{work: newWorkQueue(workers)}
+}
+
+// Submit queues fn on pool and returns a Promise settled with its result.
+func Submit[T any](pool *Pool, fn func() (T, error)) *Promise[T] {
+	return enqueue(pool.work, fn)
+}
+
+// Stats returns the current task counts of p.
+func (p *Pool) Stats() PoolStats {
+	return p.work.stats()
+}
+
+// Close stops p from accepting tasks, rejects the tasks that have not
+// started with ErrPoolClosed and waits for the running ones to finish.
+func (p *Pool) Close() {
+	p.work.close(ErrPoolClosed)
+}
@@ -0,0 +1,59 @@
+package gopromise
+
+import (
+	"testing"
+)
+
+func TestPool_Submit(t *testing.T) {
+	pool := NewPool(2)
+	defer pool.Close()
+
+	p1 := Submit(pool, func() (int, error) { return 1, nil })
+	p2 := Submit(pool, func() (string, error) { return "two", nil })
+	p3 := Submit(pool, func() (int, error) { return 0, promiseError })
+
+	res1, err := p1.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res1)
+	res2, err := p2.Await()
+	assertNotErr(t, err)
+	assertEqual(t, "two", res2)
+	_, err = p3.Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestPool_StatsAndClose(t *testing.T) {
+	pool := NewPool(1)
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	running := Submit(pool, func() (int, error) {
+		close(started)
+		<-gate
+		return 1, nil
+	})
+	<-started
+	queued := Submit(pool, func() (int, error) { return 2, nil })
+
+	stats := pool.Stats()
+	assertEqual(t, 1, stats.Queued)
+	assertEqual(t, 1, stats.Running)
+	assertEqual(t, 0, stats.Completed)
+
+	closed := make(chan struct{})
+	go func() {
+		pool.Close()
+		close(closed)
+	}()
+	_, err := queued.Await()
+	assertEqual(t, ErrPoolClosed, err)
+	close(gate)
+	<-closed
+
+	res, err := running.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+	assertEqual(t, 1, pool.Stats().Completed)
+
+	_, err = Submit(pool, func() (int, error) { return 3, nil }).Await()
+	assertEqual(t, ErrPoolClosed, err)
+}
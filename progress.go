@@ -0,0 +1,57 @@
+package gopromise
+
+import "sync"
+
+// ProgressPromise is a Promise whose executor reports progress along the way,
+// such as the bytes uploaded so far, before settling once at the end.
+type ProgressPromise[T, P any] struct {
+	*Promise[T]
+	mutex  sync.Mutex
+	subs   map[uint64]func(P)
+	nextID uint64
+}
+
+// NewWithProgress is like New but also hands exec a report function. Each
+// call of report is passed, on the calling goroutine, to the callbacks
+// registered with OnProgress at the time. Reports made after the promise has
+// settled are dropped.
+func NewWithProgress[T, P any](exec func(resolve func(T), reject func(error), report func(P)), opts ...Option) *ProgressPromise[T, P] {
+	if exec == nil {
+		panic("executor cannot be nil")
+	}
+	pp := &ProgressPromise[T, P]{subs: make(map[uint64]func(P))}
+	pp.Promise = New(func(resolve func(T), reject func(error)) {
+		exec(resolve, reject, pp.report)
+	}, opts...)
+	return pp
+}
+
+// OnProgress registers cb to be called with every progress report from now
+// on. It returns a function that removes the registration.
+func (pp *ProgressPromise[T, P]) OnProgress(cb func(progress P)) (cancel func()) {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+	id := pp.nextID
+	pp.nextID++
+	pp.subs[id] = cb
+	return func() {
+		pp.mutex.Lock()
+		defer pp.mutex.Unlock()
+		delete(pp.subs, id)
+	}
+}
+
+func (pp *ProgressPromise[T, P]) report(progress P) {
+	if pp.isSettled() {
+		return
+	}
+	pp.mutex.Lock()
+	subs := make([]func(P), 0, len(pp.subs))
+	for _, cb := range pp.subs {
+		subs = append(subs, cb)
+	}
+	pp.mutex.Unlock()
+	for _, cb := range subs {
+		cb(progress)
+	}
+}
@@ -0,0 +1,27 @@
+package gopromise
+
+import "testing"
+
+func TestNewWithProgress(t *testing.T) {
+	start := make(chan struct{})
+	p := NewWithProgress(func(resolve func(string), reject func(error), report func(int)) {
+		<-start
+		for pct := 25; pct <= 100; pct += 25 {
+			report(pct)
+		}
+		resolve("uploaded")
+		report(200)
+	})
+
+	var reports []int
+	p.OnProgress(func(pct int) { reports = append(reports, pct) })
+	cancel := p.OnProgress(func(int) { t.Error("cancelled callback called") })
+	cancel()
+	close(start)
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, "uploaded", res)
+	assertEqual(t, 4, len(reports))
+	assertEqual(t, 100, reports[3])
+}
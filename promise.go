@@ -1,98 +1,617 @@
 package gopromise
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-type promiseStatus uint16
+// State is the state of a promise. A promise starts Pending and settles
+// exactly once into one of the other states.
+type State uint16
 
 const (
-	PENDING promiseStatus = iota
-	FULFILLED
-	REJECTED
+	Pending State = iota
+	Fulfilled
+	Rejected
+	Cancelled
 )
 
+// Deprecated: the uppercase names are kept for compatibility; use Pending,
+// Fulfilled, Rejected and Cancelled.
+const (
+	PENDING   = Pending
+	FULFILLED = Fulfilled
+	REJECTED  = Rejected
+	CANCELLED = Cancelled
+)
+
+// settling is the internal status of a promise between the moment settle
+// claims it and the moment its outcome is published. It reads as Pending.
+const settling = 1 << 16
+
+func (s State) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Fulfilled:
+		return "fulfilled"
+	case Rejected:
+		return "rejected"
+	case Cancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("State(%d)", uint16(s))
+}
+
 type Promise[T any] struct {
 	value  T
 	reason error
-	status promiseStatus
-	mutex  *sync.Mutex
-	wg     *sync.WaitGroup
+	// status holds a State, or settling while settle is filling in value and
+	// reason. It is read without locking; mutex only orders settlement with
+	// the registration of callbacks and onSettle.
+	status atomic.Uint32
+	mutex  sync.Mutex
+	// done is closed once p settles, after which value, reason and status
+	// no longer change. Waiting on p is receiving from done.
+	done     chan struct{}
+	cancel   context.CancelFunc
+	cleanups *cleanupList
+	// flight links p into the list of in-flight promises Drain waits for.
+	flight   inflight
+	panicked bool
+	created  time.Time
+	name     string
+	key      string
+	lazy     *lazyStart
+	// onSettle, when set, releases resources held by p right before it is
+	// observed as settled.
+	onSettle func()
+	// callbacks run on the settling goroutine once p has settled, and
+	// continuations are then dispatched to the default Scheduler.
+	callbacks     []func()
+	continuations []func()
+	// hookID and site identify p to lifecycle hooks.
+	hookID uint64
+	site   string
+	// pooled is the Recycler entry holding p, for recycled promises.
+	pooled *pooled[T]
+
+	// derived marks promises created by the library from other promises,
+	// listed in upstream. consumers counts the derived promises that still
+	// depend on this one.
+	derived   bool
+	upstream  []source
+	consumers atomic.Int32
+	released  atomic.Bool
 }
 
-func New[T any](exec func(resolve func(T), reject func(error))) *Promise[T] {
+// New returns a Promise settled by exec, which runs on its own goroutine. It
+// honours WithTimeout and WithContext.
+//
+// The resolve and reject functions handed to exec run the OnSettled callbacks
+// of the promise before they return, but not those of Then or Catch, which
+// run as Then describes.
+func New[T any](exec func(resolve func(T), reject func(error)), opts ...Option) *Promise[T] {
 	if exec == nil {
 		panic("executor cannot be nil")
 	}
+	o := buildOptions(opts)
+	p := newNamedPromise[T](o.name)
+	p.start(exec, o)
+	p.enforce(o)
+	return p
+}
 
-	p := &Promise[T]{
-		status: PENDING,
-		mutex:  &sync.Mutex{},
-		wg:     &sync.WaitGroup{},
+// Go returns a Promise settled by the return values of fn, which runs on its
+// own goroutine: it rejects with fn's error when that is non-nil and fulfills
+// with fn's value otherwise. It accepts the same options as New.
+func Go[T any](fn func() (T, error), opts ...Option) *Promise[T] {
+	if fn == nil {
+		panic("function cannot be nil")
 	}
+	return New(func(resolve func(T), reject func(error)) {
+		val, err := fn()
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(val)
+	}, opts...)
+}
 
-	p.wg.Add(1)
+// run starts one of the library's own executors on its own goroutine.
+func run[T any](exec func(resolve func(T), reject func(error))) *Promise[T] {
+	p := newPromise[T]()
+	p.start(exec, nil)
+	return p
+}
 
-	go func() {
+func newPromise[T any]() *Promise[T] {
+	return newNamedPromise[T]("")
+}
+
+// newNamedPromise returns a pending promise named name. The name is set
+// before the promise is reported to the OnCreate hooks.
+func newNamedPromise[T any](name string) *Promise[T] {
+	p := &Promise[T]{
+		done:    make(chan struct{}),
+		created: time.Now(),
+		name:    name,
+	}
+	p.notifyCreated()
+	return p
+}
+
+// task wraps exec so that it settles p: a panic in exec, even panic(nil),
+// rejects p after being reported to the panic handler. A user executor that
+// returns without settling p rejects it with
+// ErrExecutorReturnedWithoutSettling unless it was given WithSettleLater; the
+// library's own executors may always settle later.
+func (p *Promise[T]) task(exec func(resolve func(T), reject func(error)), o *options) func() {
+	handler, policy := panicSettings(o)
+	resolve, reject := p.settlers(o)
+	return func() {
+		if o != nil && !o.inline {
+			defer acquireSlot()()
+		}
+		returned := false
 		// catch exception error happen in the executor
 		defer func() {
-			r := recover()
-			if err, ok := r.(error); ok {
-				p.reject(err)
-			} else {
-				p.reject(fmt.Errorf("%+v", r))
+			if returned {
+				if o != nil && !o.settleLater {
+					p.reject(ErrExecutorReturnedWithoutSettling)
+				}
+				return
+			}
+			err := handlePanic(recover(), handler, policy)
+			p.rejectPanic(err)
+			if policy == CrashOnPanic {
+				panic(err)
 			}
 		}()
-		exec(p.resolve, p.reject)
-	}()
-
-	return p
+		exec(resolve, reject)
+		returned = true
+	}
 }
 
-func (p *Promise[T]) resolve(val T) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+// settlers returns the resolve and reject functions handed to the user. When
+// o asks for it, they report a call made after one of them was already
+// called.
+func (p *Promise[T]) settlers(o *options) (resolve func(T), reject func(error)) {
+	if o == nil || (!o.strict && o.misuseHandler == nil) {
+		return p.resolve, p.reject
+	}
+	const (
+		resolved = iota + 1
+		rejected
+	)
+	var first atomic.Uint32
+	calls := [...]string{resolved: "resolve", rejected: "reject"}
+	check := func(call uint32) bool {
+		if first.CompareAndSwap(0, call) {
+			return true
+		}
+		err := fmt.Errorf("%w: %s called after %s", ErrSettledTwice, calls[call], calls[first.Load()])
+		if p.name != "" {
+			err = fmt.Errorf("promise %q: %w", p.name, err)
+		}
+		if o.misuseHandler == nil {
+			panic(err)
+		}
+		o.misuseHandler(err)
+		return false
+	}
+	resolve = func(val T) {
+		if check(resolved) {
+			p.resolve(val)
+		}
+	}
+	reject = func(err error) {
+		if check(rejected) {
+			p.reject(err)
+		}
+	}
+	return resolve, reject
+}
 
-	if p.status != PENDING {
+// start runs exec. Executors supplied by users come with their options: they
+// are handed to the scheduler and count against the process-wide concurrency
+// limit, unless WithInline runs them right away. The library's own
+// executors, which mostly block waiting on other promises, come with nil
+// options and run on a goroutine of their own.
+func (p *Promise[T]) start(exec func(resolve func(T), reject func(error)), o *options) {
+	task := p.task(exec, o)
+	if o == nil {
+		go task()
+		return
+	}
+	if !p.track() {
+		p.reject(ErrDraining)
+		return
+	}
+	if o.inline {
+		task()
 		return
 	}
+	if o.scheduler == nil {
+		go task()
+		return
+	}
+	var err error
+	if ps, ok := o.scheduler.(PriorityScheduler); ok && o.priority != PriorityNormal {
+		err = ps.SchedulePriority(task, o.priority)
+	} else {
+		err = o.scheduler.Schedule(task)
+	}
+	if err != nil {
+		p.reject(err)
+	}
+}
 
-	p.status = FULFILLED
-	p.value = val
-	p.wg.Done()
+func (p *Promise[T]) resolve(val T) {
+	p.settle(Fulfilled, val, nil, false)
 }
 
 func (p *Promise[T]) reject(err error) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+	var zero T
+	p.settle(Rejected, zero, err, false)
+}
 
-	if p.status != PENDING {
-		return
+// rejectPanic rejects p with err, which was recovered from a panic.
+func (p *Promise[T]) rejectPanic(err error) {
+	var zero T
+	p.settle(Rejected, zero, err, true)
+}
+
+// settle moves p out of Pending and reports whether it did; a promise that
+// has already settled is left untouched.
+func (p *Promise[T]) settle(status State, val T, err error, panicked bool) bool {
+	if !p.status.CompareAndSwap(uint32(Pending), settling) {
+		return false
 	}
 
-	p.status = REJECTED
+	p.value = val
 	p.reason = err
-	p.wg.Done()
+	p.panicked = panicked
+	if p.cancel != nil {
+		p.cancel()
+	}
+	var raise error
+	p.mutex.Lock()
+	if p.onSettle != nil {
+		p.onSettle()
+	}
+	p.settled(status, &raise)
+	p.status.Store(uint32(status))
+	close(p.done)
+	callbacks, continuations := p.callbacks, p.continuations
+	p.callbacks, p.continuations = nil, nil
+	p.mutex.Unlock()
+
+	if p.flight.owner != nil {
+		untrack(&p.flight)
+	}
+	p.notifySettled(status, err, panicked, &raise)
+	if p.cleanups != nil {
+		if status == Cancelled || errors.Is(err, ErrTimeout) {
+			p.cleanups.fire()
+		} else {
+			p.cleanups.discard()
+		}
+	}
+	for _, cb := range callbacks {
+		guard(&raise, cb)
+	}
+	if continuations != nil {
+		dispatch(continuations)
+	}
+	if raise != nil {
+		panic(raise)
+	}
+	return true
 }
 
+// settled runs the bookkeeping shared by every transition out of Pending.
+// It is called with the mutex held, before any waiter is released.
+func (p *Promise[T]) settled(status State, raise *error) {
+	if metricsEnabled.Load() {
+		settleHist.Load().observe(time.Since(p.created))
+	}
+	if j := journal.Load(); j != nil {
+		entry := JournalEntry{
+			Name:     p.name,
+			Key:      p.key,
+			Duration: time.Since(p.created),
+			Status:   status.String(),
+		}
+		if p.reason != nil {
+			entry.Error = p.reason.Error()
+		}
+		guard(raise, func() { j.sink.Record(entry) })
+	}
+}
+
+// guard runs fn, one of the callbacks, hooks or sinks that settle calls, so
+// that a panic in fn cannot keep p from settling or its continuations from
+// running. The panic is handled as Defaults asks; unless a panic handler has
+// taken it under RejectOnPanic, the first one is stored in raise, for the
+// caller to panic with once it is done.
+func guard(raise *error, fn func()) {
+	returned := false
+	defer func() {
+		if returned {
+			return
+		}
+		handler, policy := panicSettings(nil)
+		err := handlePanic(recover(), handler, policy)
+		if (handler == nil || policy != RejectOnPanic) && *raise == nil {
+			*raise = err
+		}
+	}()
+	fn()
+	returned = true
+}
+
+// closedChan is the done channel shared by promises created settled.
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
 func (p *Promise[T]) Await() (T, error) {
-	p.wg.Wait()
+	var start time.Time
+	if metricsEnabled.Load() {
+		start = time.Now()
+	}
+	val, err := p.await()
+	if !start.IsZero() {
+		awaitHist.Load().observe(time.Since(start))
+	}
+	if err != nil {
+		repanic(err)
+	}
+	return val, err
+}
+
+// MustAwait is like Await but panics with the rejection reason when p
+// rejects. It is meant for tests, initialization and other code where a
+// rejection is fatal anyway.
+func (p *Promise[T]) MustAwait() T {
+	val, err := p.Await()
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Done returns a channel that is closed once p settles, so p can take part
+// in select statements. Await returns the outcome without blocking after the
+// channel is closed.
+func (p *Promise[T]) Done() <-chan struct{} {
+	p.wake()
+	return p.done
+}
+
+// Result is the outcome of a settled promise.
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// ToChannel returns a channel that receives the outcome of p once it settles
+// and is then closed. The channel is buffered, so p's outcome is delivered
+// even if nobody is receiving yet.
+func (p *Promise[T]) ToChannel() <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+	if val, err, ok := p.TryAwait(); ok {
+		ch <- Result[T]{val, err}
+		close(ch)
+		return ch
+	}
+	go func() {
+		val, err := p.await()
+		ch <- Result[T]{val, err}
+		close(ch)
+	}()
+	return ch
+}
+
+// AwaitCtx is like Await but gives up waiting once ctx is done, returning
+// ctx.Err(). Giving up does not affect p, which keeps running and can still
+// be awaited.
+func (p *Promise[T]) AwaitCtx(ctx context.Context) (T, error) {
+	if metricsEnabled.Load() {
+		start := time.Now()
+		defer func() { awaitHist.Load().observe(time.Since(start)) }()
+	}
+	p.wake()
+	select {
+	case <-p.done:
+		if p.reason != nil {
+			repanic(p.reason)
+		}
+		return p.value, p.reason
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// ErrAwaitTimeout is returned by AwaitTimeout when the promise does not
+// settle in time. It matches ErrTimeout under errors.Is.
+var ErrAwaitTimeout = fmt.Errorf("await: %w", ErrTimeout)
+
+// AwaitTimeout is like Await but gives up waiting after d, returning
+// ErrAwaitTimeout. Giving up does not affect p, which keeps running and can
+// still be awaited.
+func (p *Promise[T]) AwaitTimeout(d time.Duration) (T, error) {
+	if metricsEnabled.Load() {
+		start := time.Now()
+		defer func() { awaitHist.Load().observe(time.Since(start)) }()
+	}
+	p.wake()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+		if p.reason != nil {
+			repanic(p.reason)
+		}
+		return p.value, p.reason
+	case <-timer.C:
+		var zero T
+		return zero, ErrAwaitTimeout
+	}
+}
+
+// State returns the current state of p.
+func (p *Promise[T]) State() State {
+	if status := p.status.Load(); status != settling {
+		return State(status)
+	}
+	return Pending
+}
+
+// String describes p for logs, e.g.
+// Promise[User]{state=pending, age=1.2s, name=fetch-user}. The age is the time
+// since p was created; the name appears when p has one.
+func (p *Promise[T]) String() string {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	typeName := typ.Name()
+	if typeName == "" {
+		typeName = typ.String()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Promise[%s]{state=%s, age=%v", typeName, p.State(), time.Since(p.created).Round(time.Millisecond))
+	if p.name != "" {
+		fmt.Fprintf(&b, ", name=%s", p.name)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// IsPending reports whether p has not settled yet.
+func (p *Promise[T]) IsPending() bool {
+	return p.State() == Pending
+}
+
+// IsFulfilled reports whether p has fulfilled.
+func (p *Promise[T]) IsFulfilled() bool {
+	return p.State() == Fulfilled
+}
+
+// IsRejected reports whether p has rejected, which includes being cancelled.
+func (p *Promise[T]) IsRejected() bool {
+	status := p.State()
+	return status == Rejected || status == Cancelled
+}
+
+// OnSettled registers cb to be called with the outcome of p once it settles.
+// cb runs on the goroutine that settles p, or right away when p has already
+// settled, so it should not block; a panic in cb is handled as PanicPolicy
+// describes. OnSettled returns p so registrations can be chained.
+func (p *Promise[T]) OnSettled(cb func(val T, err error)) *Promise[T] {
+	p.whenSettled(func() { cb(p.value, p.reason) })
+	return p
+}
+
+// whenSettled arranges for fn to run once p has settled: on the goroutine
+// that settles p, or right away when p has already settled.
+func (p *Promise[T]) whenSettled(fn func()) {
+	p.wake()
+	p.mutex.Lock()
+	if p.State() == Pending {
+		p.callbacks = append(p.callbacks, fn)
+		p.mutex.Unlock()
+		return
+	}
+	p.mutex.Unlock()
+	var raise error
+	guard(&raise, fn)
+	if raise != nil {
+		panic(raise)
+	}
+}
+
+// OnFulfilled is like OnSettled but calls cb only when p fulfills.
+func (p *Promise[T]) OnFulfilled(cb func(val T)) *Promise[T] {
+	return p.OnSettled(func(val T, err error) {
+		if err == nil {
+			cb(val)
+		}
+	})
+}
+
+// OnRejected is like OnSettled but calls cb only when p rejects, which
+// includes being cancelled.
+func (p *Promise[T]) OnRejected(cb func(err error)) *Promise[T] {
+	return p.OnSettled(func(_ T, err error) {
+		if err != nil {
+			cb(err)
+		}
+	})
+}
+
+// isSettled reports whether p has settled, without blocking.
+func (p *Promise[T]) isSettled() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// TryAwait returns the outcome of p and true when p has settled, or false
+// right away when it is still pending. It starts a lazy promise.
+func (p *Promise[T]) TryAwait() (val T, err error, settled bool) {
+	p.wake()
+	return p.peek()
+}
+
+// peek returns the outcome of p and true when p has settled, without
+// blocking.
+func (p *Promise[T]) peek() (T, error, bool) {
+	if p.State() == Pending {
+		var zero T
+		return zero, nil, false
+	}
+	return p.value, p.reason, true
+}
+
+// await blocks until p settles. Unlike Await it is not counted in the await
+// metrics, so the library's own waits don't drown out those of its callers.
+func (p *Promise[T]) await() (T, error) {
+	p.wake()
+	<-p.done
 	return p.value, p.reason
 }
 
+// Then returns a Promise that settles with the result of cb once src
+// fulfills, and rejects like src otherwise. cb runs on the default Scheduler
+// when one is set. Without one, it runs right away on the calling goroutine
+// when src has already settled, and otherwise on a goroutine shared with the
+// other callbacks waiting on src, so that settling src never waits for it.
 func Then[T, R any](src *Promise[T], cb func(val T) R) *Promise[R] {
-	if src == nil {
-		panic("must provide valid promise")
-	}
-	return New(func(resolve func(R), reject func(error)) {
-		val, err := src.Await()
+	return continuation(src, func(resolve func(R), reject func(error)) {
+		val, err := src.await()
 		if err != nil {
 			reject(err)
 			return
 		}
 		resOrProm := cb(val)
 		if rp, ok := interface{}(resOrProm).(*Promise[R]); ok {
+			if rp == nil {
+				reject(ErrNilPromise)
+				return
+			}
 			Then(rp, func(val R) R { resolve(val); return val })
 			Catch(rp, func(err error) any { reject(err); return nil })
 			return
@@ -101,103 +620,333 @@ func Then[T, R any](src *Promise[T], cb func(val T) R) *Promise[R] {
 	})
 }
 
-func Catch[T, R any](src *Promise[T], cb func(err error) R) *Promise[R] {
-	return New(func(resolve func(R), reject func(error)) {
-		_, err := src.Await()
+// Then is the method form of the Then function for callbacks that keep the
+// value type, so such steps can be chained:
+//
+//	total := fetchCart().Then(applyDiscount).Then(addTax)
+func (p *Promise[T]) Then(cb func(val T) T) *Promise[T] {
+	return Then(p, cb)
+}
+
+// ThenReturn returns a Promise that fulfills with value once src fulfills,
+// discarding the value of src. Rejections of src pass through unchanged.
+func ThenReturn[T, R any](src *Promise[T], value R) *Promise[R] {
+	return continuation(src, func(resolve func(R), reject func(error)) {
+		if _, err := src.await(); err != nil {
+			reject(err)
+			return
+		}
+		resolve(value)
+	})
+}
+
+// ToAny returns a Promise that settles like src with its value as an any, so
+// promises of different types can be kept in one slice.
+func ToAny[T any](src *Promise[T]) *Promise[any] {
+	return continuation(src, func(resolve func(any), reject func(error)) {
+		val, err := src.await()
 		if err != nil {
-			resOrProm := cb(err)
-			if rp, ok := interface{}(resOrProm).(*Promise[R]); ok {
-				Then(rp, func(val R) R { resolve(val); return val })
-				Catch(rp, func(err error) any { reject(err); return nil })
+			reject(err)
+			return
+		}
+		resolve(val)
+	})
+}
+
+// As is the inverse of ToAny: it returns a Promise that settles like src with
+// its value narrowed to R. It rejects with a *ConversionError when the value
+// is not an R. A nil value converts to the zero value of R when R is an
+// interface, pointer, slice, map, channel or function type.
+func As[R any](src *Promise[any]) *Promise[R] {
+	return continuation(src, func(resolve func(R), reject func(error)) {
+		val, err := src.await()
+		if err != nil {
+			reject(err)
+			return
+		}
+		res, ok := val.(R)
+		if !ok {
+			want := reflect.TypeOf((*R)(nil)).Elem()
+			switch want.Kind() {
+			case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
+				ok = val == nil
+			}
+			if !ok {
+				reject(&ConversionError{Value: val, Want: want})
 				return
 			}
-			resolve(resOrProm)
+		}
+		resolve(res)
+	})
+}
+
+// Catch returns a Promise that fulfills with the value returned by cb when
+// src rejects. When src fulfills, cb is not called and the value of src
+// passes through, which requires it to be an R, as it is when T and R are the
+// same type or R is any; otherwise the returned promise rejects with a
+// *ConversionError. The Catch method and OrElseGet recover within the value
+// type of src. cb runs where the callback of Then would.
+func Catch[T, R any](src *Promise[T], cb func(err error) R) *Promise[R] {
+	return continuation(src, func(resolve func(R), reject func(error)) {
+		val, err := src.await()
+		if err == nil {
+			res, ok := any(val).(R)
+			if !ok {
+				reject(&ConversionError{Value: val, Want: reflect.TypeOf((*R)(nil)).Elem()})
+				return
+			}
+			resolve(res)
+			return
+		}
+		resOrProm := cb(err)
+		if rp, ok := interface{}(resOrProm).(*Promise[R]); ok {
+			if rp == nil {
+				reject(ErrNilPromise)
+				return
+			}
+			Then(rp, func(val R) R { resolve(val); return val })
+			Catch(rp, func(err error) any { reject(err); return nil })
 			return
 		}
+		resolve(resOrProm)
+	})
+}
+
+// Catch is the method form of Catch for recovering within the value type:
+// when p rejects, the returned promise settles with the outcome of cb, which
+// may recover with a value or keep the promise rejected by returning an
+// error. When p fulfills, its value passes through and cb is not called.
+func (p *Promise[T]) Catch(cb func(err error) (T, error)) *Promise[T] {
+	return continuation(p, func(resolve func(T), reject func(error)) {
+		val, err := p.await()
+		if err != nil {
+			val, err = cb(err)
+		}
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(val)
+	})
+}
+
+// OrElse returns a Promise that fulfills with the value of src, or with
+// fallback when src is rejected.
+func OrElse[T any](src *Promise[T], fallback T) *Promise[T] {
+	return OrElseGet(src, func(error) T { return fallback })
+}
+
+// OrElseGet is like OrElse but computes the fallback from the rejection
+// reason.
+func OrElseGet[T any](src *Promise[T], supplier func(err error) T) *Promise[T] {
+	return continuation(src, func(resolve func(T), reject func(error)) {
+		val, err := src.await()
+		if err != nil {
+			resolve(supplier(err))
+			return
+		}
+		resolve(val)
+	})
+}
+
+// MapErr returns a Promise that rejects with fn applied to the rejection
+// reason of src. Fulfillment of src passes through unchanged.
+func MapErr[T any](src *Promise[T], fn func(err error) error) *Promise[T] {
+	return continuation(src, func(resolve func(T), reject func(error)) {
+		val, err := src.await()
+		if err != nil {
+			reject(fn(err))
+			return
+		}
+		resolve(val)
+	})
+}
+
+// Validate returns a Promise that fulfills with the value of src when check
+// accepts it, and rejects with the error check returns otherwise. Rejections
+// of src pass through unchanged.
+func Validate[T any](src *Promise[T], check func(val T) error) *Promise[T] {
+	return continuation(src, func(resolve func(T), reject func(error)) {
+		val, err := src.await()
+		if err == nil {
+			err = check(val)
+		}
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(val)
 	})
 }
 
 func Resolve[T any](value T) *Promise[T] {
-	return &Promise[T]{
-		value:  value,
-		status: FULFILLED,
-		mutex:  new(sync.Mutex),
-		wg:     new(sync.WaitGroup),
+	p := &Promise[T]{
+		value:   value,
+		done:    closedChan,
+		created: time.Now(),
+	}
+	p.status.Store(uint32(Fulfilled))
+	p.notifyCreated()
+	var raise error
+	p.notifySettled(Fulfilled, nil, false, &raise)
+	if raise != nil {
+		panic(raise)
 	}
+	return p
+}
+
+// Deferred returns a pending Promise together with the functions that settle
+// it, for code that cannot settle the promise from inside an executor, such
+// as event handlers. Only the first call to resolve or reject has an effect.
+// Like those handed to the executor of New, they return without waiting for
+// the callbacks of Then and Catch. It honours WithTimeout, WithContext,
+// WithStrict and WithMisuseHandler.
+func Deferred[T any](opts ...Option) (p *Promise[T], resolve func(T), reject func(error)) {
+	o := buildOptions(opts)
+	p = newNamedPromise[T](o.name)
+	p.enforce(o)
+	resolve, reject = p.settlers(o)
+	return p, resolve, reject
 }
 
 // Reject returns a Promise that has been rejected with a given error.
 func Reject[T any](err error) *Promise[T] {
-	return &Promise[T]{
-		reason: err,
-		status: REJECTED,
-		mutex:  new(sync.Mutex),
-		wg:     new(sync.WaitGroup),
+	p := &Promise[T]{
+		reason:  err,
+		done:    closedChan,
+		created: time.Now(),
 	}
+	p.status.Store(uint32(Rejected))
+	p.notifyCreated()
+	var raise error
+	p.notifySettled(Rejected, err, false, &raise)
+	if raise != nil {
+		panic(raise)
+	}
+	return p
 }
 
-type pair[T, R any] struct {
-	first  T
-	second R
+// All returns a Promise that fulfills with the values of promises, in order,
+// once all of them have fulfilled, or rejects with the first rejection,
+// wrapped in an IndexedError telling which input it comes from. With no
+// promises, it fulfills right away with an empty slice.
+//
+// On rejection, All gives up its claim on the other inputs, which cancels
+// those that Cancel would cancel upstream: inputs that were derived or created
+// by NewWithContext and that nothing else depends on. The rejection reason is
+// their cause.
+func All[T any](promises ...*Promise[T]) *Promise[[]T] {
+	return AllWith(promises)
 }
 
-func All[T any](promises ...*Promise[T]) *Promise[[]T] {
+// AllWith is like All but accepts options. It honours WithFailFast,
+// WithItemTimeout, WithContext and WithTimeout. With WithFailFast(false), it
+// waits for every input and rejects with an *AggregateError holding their
+// rejection reasons.
+func AllWith[T any](promises []*Promise[T], opts ...Option) *Promise[[]T] {
+	promises = orRejected(promises)
+	o := buildOptions(opts)
 	if len(promises) == 0 {
-		return nil
-	}
-	return New(func(resolve func([]T), reject func(error)) {
-		doneChan := make(chan bool, len(promises))
-		errChan := make(chan error, 1)
-		values := make([]T, len(promises))
-		for idx, p := range promises {
-			idx := idx
-			_ = Then(p, func(val T) T {
-				values[idx] = val
-				doneChan <- true
-				return val
-			})
-			_ = Catch(p, func(err error) error {
-				errChan <- err
-				return err
+		all := newNamedPromise[[]T](o.name)
+		all.resolve([]T{})
+		return all
+	}
+	all := newNamedDerived[[]T](o.name, sources(promises)...)
+	values := make([]T, len(promises))
+	errs := make([]error, len(promises))
+	reported := make([]atomic.Bool, len(promises))
+	var remaining atomic.Int64
+	var failed atomic.Bool
+	remaining.Store(int64(len(promises)))
+	// report collects the outcome of the input at idx, which either settled
+	// or timed out, whichever came first.
+	report := func(idx int, val T, err error) {
+		if reported[idx].Swap(true) {
+			return
+		}
+		if err == nil {
+			values[idx] = val
+		} else if !o.collectAll {
+			all.reject(IndexedError{Index: idx, Err: err})
+			all.releaseUpstream(err)
+		} else {
+			errs[idx] = err
+			failed.Store(true)
+		}
+		if remaining.Add(-1) > 0 {
+			return
+		}
+		if failed.Load() {
+			all.reject(aggregate(errs))
+			return
+		}
+		all.resolve(values)
+	}
+	for idx, p := range promises {
+		idx, p := idx, p
+		var timer *time.Timer
+		if o.itemTimeout > 0 {
+			timer = time.AfterFunc(o.itemTimeout, func() {
+				var zero T
+				report(idx, zero, &TimeoutError{After: o.itemTimeout, PromiseName: p.name})
 			})
 		}
-
-		for idx := 0; idx < len(promises); idx++ {
-			select {
-			case <-doneChan:
-			case err := <-errChan:
-				reject(err)
-				return
+		p.whenSettled(func() {
+			if timer != nil {
+				timer.Stop()
 			}
-		}
-		resolve(values)
-	})
+			report(idx, p.value, p.reason)
+		})
+	}
+	all.enforce(o)
+	return all
 }
 
+// Race returns a Promise that settles like the first of promises to settle.
+// With no promises, it rejects with ErrNoPromises.
 func Race[T any](promises ...*Promise[T]) *Promise[T] {
+	return RaceWith(promises)
+}
+
+// RaceWith is like Race but accepts options. It honours WithDeterministic,
+// WithContext and WithTimeout.
+//
+// Once the race is decided, RaceWith gives up its claim on the losing
+// inputs, which cancels those that Cancel would cancel upstream: inputs that
+// were derived or created by NewWithContext and that nothing else depends
+// on. Their cause is ErrRaceLost.
+func RaceWith[T any](promises []*Promise[T], opts ...Option) *Promise[T] {
+	promises = orRejected(promises)
 	if len(promises) == 0 {
-		return nil
+		return Reject[T](ErrNoPromises)
 	}
-	return New(func(resolve func(T), reject func(error)) {
-		valueChan := make(chan T, 1)
-		errChan := make(chan error, 1)
+	o := buildOptions(opts)
+	race := newNamedDerived[T](o.name, sources(promises)...)
+	if o.deterministic {
 		for _, p := range promises {
-			_ = Then(p, func(val T) T {
-				valueChan <- val
-				return val
-			})
-			_ = Catch(p, func(err error) error {
-				errChan <- err
-				return err
-			})
-		}
-
-		select {
-		case val := <-valueChan:
-			resolve(val)
-		case err := <-errChan:
-			reject(err)
+			if val, err, ok := p.peek(); ok {
+				if err != nil {
+					race.reject(err)
+				} else {
+					race.resolve(val)
+				}
+				race.releaseUpstream(ErrRaceLost)
+				return race
+			}
 		}
-	})
-
+	}
+	for _, p := range promises {
+		p := p
+		p.whenSettled(func() {
+			status := Fulfilled
+			if p.reason != nil {
+				status = Rejected
+			}
+			if race.settle(status, p.value, p.reason, false) {
+				race.releaseUpstream(ErrRaceLost)
+			}
+		})
+	}
+	race.enforce(o)
+	return race
 }
@@ -1,10 +1,13 @@
 package gopromise
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -99,6 +102,217 @@ func TestPromise_Then(t *testing.T) {
 	assertNotErr(t, err)
 }
 
+func TestThen_SettledSourceRunsInline(t *testing.T) {
+	p := Then(Resolve(1), func(val int) int { return val + 1 })
+	assert(t, p.IsFulfilled(), "continuation of a settled promise should run right away")
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+
+	c := Catch(Reject[int](promiseError), func(err error) any { return err.Error() })
+	assert(t, c.IsFulfilled(), "continuation of a settled promise should run right away")
+}
+
+func TestThen_ResolveDoesNotWait(t *testing.T) {
+	src, resolve, _ := Deferred[int]()
+	var mutex sync.Mutex
+	p := src.Then(func(val int) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return val + 1
+	})
+
+	mutex.Lock()
+	resolve(1)
+	mutex.Unlock()
+	assertEqual(t, 2, p.MustAwait())
+}
+
+func TestThen_NoParkedGoroutines(t *testing.T) {
+	src, resolve, _ := Deferred[int]()
+	before := runtime.NumGoroutine()
+	p := src
+	for i := 0; i < 1000; i++ {
+		p = p.Then(func(val int) int { return val + 1 })
+	}
+	assert(t, runtime.NumGoroutine() < before+100, "continuations should not park goroutines")
+
+	resolve(0)
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1000, res)
+}
+
+func TestThenReturn(t *testing.T) {
+	type status string
+
+	res, err := ThenReturn(Resolve(42), status("done")).Await()
+	assertNotErr(t, err)
+	assertEqual(t, status("done"), res)
+
+	_, err = ThenReturn(Reject[int](promiseError), status("done")).Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestGo(t *testing.T) {
+	res, err := Go(func() (int, error) { return 1, nil }).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+
+	_, err = Go(func() (int, error) { return 1, promiseError }).Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestPromise_Done(t *testing.T) {
+	p := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(10 * time.Millisecond)
+		resolve(1)
+	})
+
+	select {
+	case <-p.Done():
+		t.Fatal("promise should still be pending")
+	default:
+	}
+
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("promise did not settle")
+	}
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+
+	<-Reject[int](promiseError).Done()
+}
+
+func TestPromise_ToChannel(t *testing.T) {
+	ch := sleepy(10*time.Millisecond, 1, nil)().ToChannel()
+	select {
+	case res := <-ch:
+		assertNotErr(t, res.Err)
+		assertEqual(t, 1, res.Value)
+	case <-time.After(time.Second):
+		t.Fatal("promise did not settle")
+	}
+	_, ok := <-ch
+	assert(t, !ok, "channel should be closed after the result")
+
+	res := <-Reject[int](promiseError).ToChannel()
+	assertEqual(t, promiseError, res.Err)
+}
+
+type panickingSink struct{}
+
+func (panickingSink) Record(JournalEntry) { panic("sink") }
+
+func TestSettle_PanickingCallbacks(t *testing.T) {
+	var recovered []any
+	var mutex sync.Mutex
+	SetDefaults(Config{PanicHandler: func(r any) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		recovered = append(recovered, r)
+	}})
+	defer SetDefaults(Config{})
+	defer RegisterHooks(Hooks{OnResolve: func(PromiseInfo) { panic("hook") }})()
+	SetJournal(panickingSink{})
+	defer SetJournal(nil)
+
+	src, resolve, _ := Deferred[int]()
+	src.OnSettled(func(int, error) { panic("callback") })
+	derived := src.Then(func(val int) int { return val + 1 })
+	resolve(1)
+
+	assertEqual(t, 1, src.MustAwait())
+	assertEqual(t, 2, derived.MustAwait())
+	mutex.Lock()
+	defer mutex.Unlock()
+	assertEqual(t, "[sink hook callback]", fmt.Sprint(recovered[:3]))
+}
+
+func TestSettle_PanickingCallbackRaised(t *testing.T) {
+	raised := func(fn func()) (err error) {
+		defer func() {
+			panicErr, ok := recover().(*PanicError)
+			if ok {
+				err = panicErr
+			}
+		}()
+		fn()
+		return nil
+	}
+
+	src, resolve, _ := Deferred[int]()
+	src.OnSettled(func(int, error) { panic("before") })
+	derived := src.Then(func(val int) int { return val + 1 })
+	assertEqual(t, "before", raised(func() { resolve(1) }).Error())
+	assertEqual(t, 1, src.MustAwait())
+	assertEqual(t, 2, derived.MustAwait())
+	assertEqual(t, "after", raised(func() {
+		src.OnSettled(func(int, error) { panic("after") })
+	}).Error())
+
+	var handled any
+	SetDefaults(Config{PanicHandler: func(r any) { handled = r }})
+	assertNil(t, raised(func() { src.OnSettled(func(int, error) { panic("handled") }) }))
+	assertEqual(t, "handled", handled)
+
+	SetDefaults(Config{PanicHandler: func(r any) { handled = r }, PanicPolicy: CrashOnPanic})
+	defer SetDefaults(Config{})
+	assertEqual(t, "crash", raised(func() { src.OnSettled(func(int, error) { panic("crash") }) }).Error())
+	assertEqual(t, "crash", handled)
+}
+
+func TestPromise_OnSettled(t *testing.T) {
+	release, done := make(chan struct{}), make(chan struct{})
+	p := New(func(resolve func(int), reject func(error)) {
+		<-release
+		resolve(1)
+	})
+
+	var calls []string
+	p.OnFulfilled(func(val int) { calls = append(calls, fmt.Sprint("fulfilled ", val)) }).
+		OnRejected(func(error) { calls = append(calls, "rejected") }).
+		OnSettled(func(val int, err error) { close(done) })
+	close(release)
+	<-done
+
+	Reject[int](promiseError).OnRejected(func(err error) { calls = append(calls, err.Error()) })
+	assertEqual(t, "[fulfilled 1 Promise Error]", fmt.Sprint(calls))
+}
+
+func TestPromise_AwaitCtx(t *testing.T) {
+	stuck := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(time.Second)
+		resolve(1)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := stuck.AwaitCtx(ctx)
+	assertEqual(t, context.DeadlineExceeded, err)
+
+	res, err := Resolve(42).AwaitCtx(context.Background())
+	assertNotErr(t, err)
+	assertEqual(t, 42, res)
+}
+
+func TestPromise_AwaitTimeout(t *testing.T) {
+	slow := New(func(resolve func(int), reject func(error)) {
+		time.Sleep(50 * time.Millisecond)
+		resolve(1)
+	})
+
+	_, err := slow.AwaitTimeout(10 * time.Millisecond)
+	assertEqual(t, ErrAwaitTimeout, err)
+
+	res, err := slow.AwaitTimeout(time.Second)
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+}
+
 func TestPromise_Catch(t *testing.T) {
 	p1 := New(func(resolve func(any), reject func(error)) {
 		reject(promiseError)
@@ -123,6 +337,71 @@ func TestPromise_Catch(t *testing.T) {
 	assertEqual(t, res, "Tadaa")
 }
 
+func TestCatch_PassesValueThrough(t *testing.T) {
+	recovered := func(err error) int { return -1 }
+	res, err := Catch(Resolve(42), recovered).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 42, res)
+
+	anyRes, err := Catch(Resolve(42), func(err error) any { return nil }).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 42, anyRes)
+
+	_, err = Catch(Resolve("42"), recovered).Await()
+	var convErr *ConversionError
+	assert(t, errors.As(err, &convErr), "expected a *ConversionError")
+}
+
+func TestOrElse(t *testing.T) {
+	p1 := OrElse(Reject[int](promiseError), 7)
+	res, err := p1.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 7, res)
+
+	p2 := OrElse(Resolve(42), 7)
+	res, err = p2.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 42, res)
+}
+
+func TestOrElseGet(t *testing.T) {
+	p := OrElseGet(Reject[string](promiseError), func(err error) string {
+		return "fallback: " + err.Error()
+	})
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, "fallback: Promise Error", res)
+}
+
+func TestMapErr(t *testing.T) {
+	wrap := func(err error) error { return fmt.Errorf("loading user: %w", err) }
+
+	_, err := MapErr(Reject[int](promiseError), wrap).Await()
+	assertEqual(t, "loading user: Promise Error", err.Error())
+	assert(t, errors.Is(err, promiseError))
+
+	res, err := MapErr(Resolve(1), wrap).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+}
+
+func TestValidate(t *testing.T) {
+	positive := func(v int) error {
+		if v <= 0 {
+			return promiseError
+		}
+		return nil
+	}
+
+	res, err := Validate(Resolve(3), positive).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, res)
+
+	_, err = Validate(Resolve(-1), positive).Await()
+	assertEqual(t, promiseError, err)
+}
+
 func TestPromise_Panic(t *testing.T) {
 	p1 := New(func(resolve func(any), reject func(error)) {
 		panic(nil)
@@ -135,19 +414,63 @@ func TestPromise_Panic(t *testing.T) {
 	})
 
 	val, err := p1.Await()
-	assertErr(t, err)
-	assertEqual(t, "<nil>", err.Error())
+	var panicErr *PanicError
+	assert(t, errors.As(err, &panicErr), "panic(nil) should reject with a *PanicError")
+	assertNil(t, panicErr.Value)
+	assertEqual(t, "panic(nil)", err.Error())
 	assertNil(t, val)
 
 	val, err = p2.Await()
-	assertErr(t, err)
+	assert(t, errors.As(err, &panicErr), "expected a *PanicError")
+	assertEqual(t, "random error", panicErr.Value)
 	assertEqual(t, "random error", err.Error())
 	assertNil(t, val)
 
 	val, err = p3.Await()
-	assertErr(t, err)
-	assertEqual(t, err, promiseError)
+	assert(t, errors.Is(err, promiseError), "a panic with an error should wrap it")
+	assertEqual(t, promiseError.Error(), err.Error())
 	assertNil(t, val)
+	assert(t, errors.As(err, &panicErr), "expected a *PanicError")
+	assert(t, strings.Contains(panicErr.StackTrace(), "promise_test.go"), "stack should show where the panic happened")
+	assert(t, strings.Contains(fmt.Sprintf("%+v", err), panicErr.StackTrace()), "%+v should print the stack")
+	assertEqual(t, promiseError.Error(), fmt.Sprintf("%v", err))
+}
+
+func TestPanic_ErrorChainSurvivesCombinators(t *testing.T) {
+	failing := New(func(resolve func(int), reject func(error)) {
+		panic(fmt.Errorf("loading user: %w", promiseError))
+	})
+	chained := Then(failing, func(val int) int { return val + 1 })
+	_, err := AllWith([]*Promise[int]{Resolve(1), chained}, WithFailFast(false)).Await()
+
+	assert(t, errors.Is(err, promiseError), "errors.Is should see through the chain")
+	var panicErr *PanicError
+	assert(t, errors.As(err, &panicErr), "errors.As should find the *PanicError")
+	var indexed IndexedError
+	assert(t, errors.As(err, &indexed), "errors.As should find the IndexedError")
+	assertEqual(t, 1, indexed.Index)
+}
+
+func TestNilPromises(t *testing.T) {
+	var nilPromise *Promise[int]
+	_, err := Then(nilPromise, func(val int) int { return val }).Await()
+	assertEqual(t, ErrNilPromise, err)
+	_, err = Catch(nilPromise, func(err error) int { return 0 }).Await()
+	assertEqual(t, ErrNilPromise, err)
+	_, err = nilPromise.Then(func(val int) int { return val }).Await()
+	assertEqual(t, ErrNilPromise, err)
+
+	_, err = All(Resolve(1), nil).Await()
+	var indexed IndexedError
+	assert(t, errors.As(err, &indexed), "expected an IndexedError")
+	assertEqual(t, 1, indexed.Index)
+	assert(t, errors.Is(err, ErrNilPromise))
+
+	_, err = Race(nil, Resolve(1)).Await()
+	assertEqual(t, ErrNilPromise, err)
+
+	_, err = Then(Resolve(1), func(int) any { return (*Promise[any])(nil) }).Await()
+	assertEqual(t, ErrNilPromise, err)
 }
 
 func TestAll_AllSuccess(t *testing.T) {
@@ -170,6 +493,47 @@ func TestAll_AllSuccess(t *testing.T) {
 	}
 }
 
+func TestToAnyAndAs(t *testing.T) {
+	mixed := []*Promise[any]{ToAny(Resolve(1)), ToAny(Resolve("two")), ToAny(Reject[int](promiseError))}
+
+	n, err := As[int](mixed[0]).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, n)
+
+	_, err = As[int](mixed[1]).Await()
+	var convErr *ConversionError
+	assert(t, errors.As(err, &convErr), "expected a *ConversionError")
+	assertEqual(t, "cannot convert string to int", err.Error())
+
+	_, err = As[string](mixed[2]).Await()
+	assertEqual(t, promiseError, err)
+
+	e, err := As[error](Resolve[any](nil)).Await()
+	assertNotErr(t, err)
+	assertNil(t, e)
+}
+
+func TestAllRace_NoGoroutinePerInput(t *testing.T) {
+	resolvers := make([]func(int), 1000)
+	promises := make([]*Promise[int], len(resolvers))
+	for idx := range promises {
+		promises[idx], resolvers[idx], _ = Deferred[int]()
+	}
+	before := runtime.NumGoroutine()
+	all, race := All(promises...), Race(promises...)
+	assert(t, runtime.NumGoroutine() < before+100, "All and Race should not start a goroutine per input")
+
+	for idx, resolve := range resolvers {
+		resolve(idx)
+	}
+	res, err := all.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 999, res[999])
+	first, err := race.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 0, first)
+}
+
 func TestAll_WithRejection(t *testing.T) {
 	p1 := New(func(resolve func(int), reject func(error)) {
 		resolve(1)
@@ -186,7 +550,8 @@ func TestAll_WithRejection(t *testing.T) {
 
 	assertErr(t, err)
 	assertNil(t, res)
-	assertEqual(t, promiseError, err)
+	assert(t, errors.Is(err, promiseError))
+	assertEqual(t, 1, err.(IndexedError).Index)
 }
 
 func TestAll_AllRejection(t *testing.T) {
@@ -205,13 +570,15 @@ func TestAll_AllRejection(t *testing.T) {
 
 	assertErr(t, err)
 	assertNil(t, res)
-	assertEqual(t, promiseError, err)
+	assert(t, errors.Is(err, promiseError))
 }
 
 func TestAll_EmptyList(t *testing.T) {
 	var empty []*Promise[any]
-	p := All(empty...)
-	assertNil(t, p)
+	res, err := All(empty...).Await()
+	assertNotErr(t, err)
+	assertNotNil(t, res)
+	assertEqual(t, 0, len(res))
 }
 
 func TestRace_AllSuccess(t *testing.T) {
@@ -280,6 +647,137 @@ func TestRace_AllRejection(t *testing.T) {
 
 func TestRace_EmptyList(t *testing.T) {
 	var empty []*Promise[any]
-	p := Race(empty...)
-	assertNil(t, p)
+	_, err := Race(empty...).Await()
+	assertEqual(t, ErrNoPromises, err)
+}
+
+func TestRaceWith_Deterministic(t *testing.T) {
+	for idx := 0; idx < 20; idx++ {
+		p := RaceWith([]*Promise[int]{Resolve(1), Reject[int](promiseError), Resolve(3)}, WithDeterministic())
+		res, err := p.Await()
+		assertNotErr(t, err)
+		assertEqual(t, 1, res)
+	}
+
+	p := RaceWith([]*Promise[int]{Reject[int](promiseError), Resolve(2)}, WithDeterministic())
+	_, err := p.Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestDeferred(t *testing.T) {
+	p, resolve, reject := Deferred[int]()
+	go func() {
+		resolve(42)
+		reject(promiseError)
+	}()
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 42, res)
+
+	rejected, _, rejectFn := Deferred[int](WithTimeout(time.Second))
+	rejectFn(promiseError)
+	_, err = rejected.Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestPromise_StateInspection(t *testing.T) {
+	p, resolve, _ := Deferred[int]()
+	assert(t, p.IsPending())
+	assert(t, !p.IsFulfilled() && !p.IsRejected())
+
+	resolve(1)
+	assert(t, p.IsFulfilled())
+	assert(t, !p.IsPending() && !p.IsRejected())
+
+	assert(t, Reject[int](promiseError).IsRejected())
+	cancelled, _, _ := Deferred[int]()
+	cancelled.Cancel(nil)
+	assert(t, cancelled.IsRejected(), "a cancelled promise counts as rejected")
+}
+
+func TestPromise_State(t *testing.T) {
+	p, resolve, _ := Deferred[int]()
+	assertEqual(t, Pending, p.State())
+	resolve(1)
+	assertEqual(t, Fulfilled, p.State())
+	assertEqual(t, Rejected, Reject[int](promiseError).State())
+
+	p.Cancel(nil)
+	assertEqual(t, Fulfilled, p.State(), "cancelling a settled promise has no effect")
+	cancelled, _, _ := Deferred[int]()
+	cancelled.Cancel(nil)
+	assertEqual(t, Cancelled, cancelled.State())
+	assertEqual(t, "cancelled", cancelled.State().String())
+	assertEqual(t, PENDING, Pending)
+}
+
+func TestPromise_TryAwait(t *testing.T) {
+	p, resolve, _ := Deferred[int]()
+	_, _, settled := p.TryAwait()
+	assert(t, !settled)
+
+	resolve(7)
+	res, err, settled := p.TryAwait()
+	assert(t, settled)
+	assertNotErr(t, err)
+	assertEqual(t, 7, res)
+
+	_, err, settled = Reject[int](promiseError).TryAwait()
+	assert(t, settled)
+	assertEqual(t, promiseError, err)
+}
+
+func TestPromise_MustAwait(t *testing.T) {
+	assertEqual(t, 3, Resolve(3).MustAwait())
+
+	defer func() {
+		assertEqual(t, promiseError, recover())
+	}()
+	Reject[int](promiseError).MustAwait()
+	t.Fatal("MustAwait should panic on rejection")
+}
+
+func TestPromise_String(t *testing.T) {
+	type User struct{ ID int }
+	p, _, _ := Deferred[User]()
+	p.name = "fetch-user"
+	str := p.String()
+	assert(t, strings.HasPrefix(str, "Promise[User]{state=pending, age="), str)
+	assert(t, strings.HasSuffix(str, "s, name=fetch-user}"), str)
+
+	str = fmt.Sprint(Resolve([]int{1}))
+	assert(t, strings.HasPrefix(str, "Promise[[]int]{state=fulfilled, age="), str)
+	assert(t, strings.HasSuffix(str, "s}"), str)
+}
+
+func TestPromise_ThenMethod(t *testing.T) {
+	double := func(v int) int { return v * 2 }
+	res, err := Resolve(3).Then(double).Then(func(v int) int { return v + 1 }).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 7, res)
+
+	_, err = Reject[int](promiseError).Then(double).Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestPromise_CatchMethod(t *testing.T) {
+	notFound := errors.New("not found")
+	recoverNotFound := func(err error) (string, error) {
+		if errors.Is(err, notFound) {
+			return "guest", nil
+		}
+		return "", fmt.Errorf("loading user: %w", err)
+	}
+
+	res, err := Reject[string](notFound).Catch(recoverNotFound).Await()
+	assertNotErr(t, err)
+	assertEqual(t, "guest", res)
+
+	_, err = Reject[string](promiseError).Catch(recoverNotFound).Await()
+	assertEqual(t, "loading user: Promise Error", err.Error())
+
+	res, err = Resolve("ada").Catch(recoverNotFound).Await()
+	assertNotErr(t, err)
+	assertEqual(t, "ada", res)
 }
@@ -0,0 +1,77 @@
+package gopromise
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Promisify adapts fn, which must be a function whose last result is an
+// error, to return a promise instead. The returned function calls fn with args
+// on its own goroutine and fulfills with fn's other results, in order, or
+// rejects with fn's error when that is non-nil. Arguments that do not match
+// fn's parameters reject the promise without calling fn.
+//
+// Promisify panics when fn is not such a function.
+func Promisify(fn any) func(args ...any) *Promise[[]any] {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumOut() == 0 || t.Out(t.NumOut()-1) != errorType {
+		panic(fmt.Sprintf("gopromise: Promisify needs a function whose last result is an error, got %v", t))
+	}
+	return func(args ...any) *Promise[[]any] {
+		return New(func(resolve func([]any), reject func(error)) {
+			in, err := promisifyArgs(t, args)
+			if err != nil {
+				reject(err)
+				return
+			}
+			out := v.Call(in)
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				reject(err)
+				return
+			}
+			vals := make([]any, len(out)-1)
+			for idx := range vals {
+				vals[idx] = out[idx].Interface()
+			}
+			resolve(vals)
+		})
+	}
+}
+
+// promisifyArgs converts args to the parameters of the function type t. A nil
+// argument stands for the zero value of its parameter.
+func promisifyArgs(t reflect.Type, args []any) ([]reflect.Value, error) {
+	fixed := t.NumIn()
+	if t.IsVariadic() {
+		fixed--
+	}
+	if len(args) < fixed || (!t.IsVariadic() && len(args) > fixed) {
+		return nil, fmt.Errorf("promisify: %v called with %d arguments", t, len(args))
+	}
+	in := make([]reflect.Value, len(args))
+	for idx, arg := range args {
+		var param reflect.Type
+		if idx < fixed {
+			param = t.In(idx)
+		} else {
+			param = t.In(fixed).Elem()
+		}
+		if arg == nil {
+			switch param.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+				in[idx] = reflect.Zero(param)
+				continue
+			}
+			return nil, fmt.Errorf("promisify: argument %d: nil is not assignable to %v", idx, param)
+		}
+		val := reflect.ValueOf(arg)
+		if !val.Type().AssignableTo(param) {
+			return nil, fmt.Errorf("promisify: argument %d: %v is not assignable to %v", idx, val.Type(), param)
+		}
+		in[idx] = val
+	}
+	return in, nil
+}
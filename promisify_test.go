@@ -0,0 +1,36 @@
+package gopromise
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPromisify(t *testing.T) {
+	atoi := Promisify(strconv.Atoi)
+	res, err := atoi("42").Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, len(res))
+	assertEqual(t, 42, res[0])
+
+	_, err = atoi("x").Await()
+	assertErr(t, err)
+
+	_, err = atoi(42).Await()
+	assertErr(t, err)
+
+	join := Promisify(func(sep string, parts ...string) (string, int, error) {
+		return strings.Join(parts, sep), len(parts), nil
+	})
+	res, err = join(",", "a", "b").Await()
+	assertNotErr(t, err)
+	assertEqual(t, "a,b", res[0])
+	assertEqual(t, 2, res[1])
+}
+
+func TestPromisify_NotAFunction(t *testing.T) {
+	defer func() {
+		assertNotNil(t, recover(), "Promisify should panic")
+	}()
+	Promisify(strings.ToUpper)
+}
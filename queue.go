@@ -0,0 +1,203 @@
+package gopromise
+
+import (
+	"sync"
+)
+
+// Queue runs tasks in the order they were enqueued, at most a fixed number
+// at a time, handing each submitter a promise of its task's result.
+type Queue[T any] struct {
+	work *workQueue
+}
+
+// NewQueue returns a Queue that runs up to concurrency tasks at a time.
+func NewQueue[T any](concurrency int) *Queue[T] {
+	if concurrency <= 0 {
+		panic("concurrency must be positive")
+	}
+	return &Queue[T]{work: newWorkQueue(concurrency)}
+}
+
+// Enqueue adds fn to the queue and returns a Promise settled with its result
+// once it has run. Tasks start in the order they were enqueued. A promise
+// cancelled while its task is still queued skips the task.
+func (q *Queue[T]) Enqueue(fn func() (T, error)) *Promise[T] {
+	return enqueue(q.work, fn)
+}
+
+// Len returns the number of tasks waiting to start.
+func (q *Queue[T]) Len() int {
+	return q.work.stats().Queued
+}
+
+// Pause stops q from starting tasks. Tasks already running carry on.
+func (q *Queue[T]) Pause() {
+	q.work.setPaused(true)
+}
+
+// Resume lets q start tasks again after Pause.
+func (q *Queue[T]) Resume() {
+	q.work.setPaused(false)
+}
+
+// workQueue runs jobs in FIFO order on up to concurrency goroutines, which
+// are started as jobs arrive and exit once the queue is empty.
+type workQueue struct {
+	mutex       sync.Mutex
+	jobs        []queueJob
+	concurrency int
+	running     int
+	completed   int
+	paused      bool
+	closed      error
+	// idle is signalled when the last worker exits.
+	idle sync.Cond
+}
+
+func newWorkQueue(concurrency int) *workQueue {
+	w := &workQueue{concurrency: concurrency}
+	w.idle.L = &w.mutex
+	return w
+}
+
+type queueJob struct {
+	run  func()
+	fail func(error)
+}
+
+// enqueue adds fn to w and returns the promise it settles.
+func enqueue[T any](w *workQueue, fn func() (T, error)) *Promise[T] {
+	if fn == nil {
+		panic("task cannot be nil")
+	}
+	p := newPromise[T]()
+	if !p.track() {
+		p.reject(ErrDraining)
+		return p
+	}
+	w.push(queueJob{
+		run: func() {
+			if !p.isSettled() {
+				settleWith(p, fn)
+			}
+		},
+		fail: p.reject,
+	})
+	return p
+}
+
+func (w *workQueue) push(job queueJob) {
+	w.mutex.Lock()
+	if w.closed != nil {
+		err := w.closed
+		w.mutex.Unlock()
+		job.fail(err)
+		return
+	}
+	w.jobs = append(w.jobs, job)
+	workers := w.claimWorkers()
+	w.mutex.Unlock()
+	w.spawn(workers)
+}
+
+func (w *workQueue) setPaused(paused bool) {
+	w.mutex.Lock()
+	w.paused = paused
+	workers := w.claimWorkers()
+	w.mutex.Unlock()
+	w.spawn(workers)
+}
+
+// close refuses further jobs with err, fails the queued ones with it and
+// waits for the running ones to finish.
+func (w *workQueue) close(err error) {
+	w.mutex.Lock()
+	if w.closed == nil {
+		w.closed = err
+	}
+	jobs := w.jobs
+	w.jobs = nil
+	w.mutex.Unlock()
+
+	for _, job := range jobs {
+		job.fail(err)
+	}
+
+	w.mutex.Lock()
+	for w.running > 0 {
+		w.idle.Wait()
+	}
+	w.mutex.Unlock()
+}
+
+// claimWorkers returns how many more workers w needs and counts them as
+// running. It is called with w.mutex held.
+func (w *workQueue) claimWorkers() int {
+	if w.paused {
+		return 0
+	}
+	n := w.concurrency - w.running
+	if n > len(w.jobs) {
+		n = len(w.jobs)
+	}
+	if n < 0 {
+		n = 0
+	}
+	w.running += n
+	return n
+}
+
+func (w *workQueue) spawn(workers int) {
+	for ; workers > 0; workers-- {
+		go w.work()
+	}
+}
+
+// work runs queued jobs until the queue is empty or paused.
+func (w *workQueue) work() {
+	w.mutex.Lock()
+	for !w.paused && len(w.jobs) > 0 {
+		job := w.jobs[0]
+		w.jobs[0] = queueJob{}
+		w.jobs = w.jobs[1:]
+		w.mutex.Unlock()
+
+		job.run()
+
+		w.mutex.Lock()
+		w.completed++
+	}
+	w.running--
+	if w.running == 0 {
+		w.idle.Broadcast()
+	}
+	w.mutex.Unlock()
+}
+
+func (w *workQueue) stats() PoolStats {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return PoolStats{Queued: len(w.jobs), Running: w.running, Completed: w.completed}
+}
+
+// settleWith settles p with the outcome of fn, rejecting it when fn panics.
+func settleWith[T any](p *Promise[T], fn func() (T, error)) {
+	returned := false
+	defer func() {
+		if !returned {
+			handler, policy := panicSettings(nil)
+			err := handlePanic(recover(), handler, policy)
+			p.rejectPanic(err)
+			if policy == CrashOnPanic {
+				panic(err)
+			}
+		}
+	}()
+	val, err := fn()
+	returned = true
+	if err != nil {
+		p.reject(err)
+		return
+	}
+	p.resolve(val)
+}
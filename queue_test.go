@@ -0,0 +1,59 @@
+package gopromise
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	q := NewQueue[int](1)
+	var mutex sync.Mutex
+	var order []int
+	promises := make([]*Promise[int], 5)
+	for idx := range promises {
+		idx := idx
+		promises[idx] = q.Enqueue(func() (int, error) {
+			mutex.Lock()
+			order = append(order, idx)
+			mutex.Unlock()
+			return idx * 10, nil
+		})
+	}
+
+	res, err := All(promises...).Await()
+	assertNotErr(t, err)
+	for idx := range res {
+		assertEqual(t, idx*10, res[idx])
+		assertEqual(t, idx, order[idx], "tasks should run in FIFO order")
+	}
+}
+
+func TestQueue_PauseResume(t *testing.T) {
+	q := NewQueue[int](2)
+	q.Pause()
+	p1 := q.Enqueue(func() (int, error) { return 1, nil })
+	p2 := q.Enqueue(func() (int, error) { return 0, promiseError })
+	assertEqual(t, 2, q.Len())
+
+	_, err := p1.AwaitTimeout(20 * time.Millisecond)
+	assertEqual(t, ErrAwaitTimeout, err, "a paused queue should not start tasks")
+
+	q.Resume()
+	res, err := p1.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+	_, err = p2.Await()
+	assertEqual(t, promiseError, err)
+	assertEqual(t, 0, q.Len())
+}
+
+func TestQueue_Panic(t *testing.T) {
+	q := NewQueue[int](1)
+	_, err := q.Enqueue(func() (int, error) { panic("boom") }).Await()
+	assertEqual(t, "boom", err.Error())
+
+	res, err := q.Enqueue(func() (int, error) { return 2, nil }).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+}
@@ -0,0 +1,78 @@
+package gopromise
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket: it holds up to burst tokens and gains one
+// every interval. Each Acquire takes a token, waiting for one when the bucket
+// is empty. Waiters are served in the order they called Acquire.
+type RateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that allows one acquisition every
+// interval on average and bursts of up to burst acquisitions. The bucket
+// starts full.
+func NewRateLimiter(interval time.Duration, burst int) *RateLimiter {
+	if interval <= 0 || burst <= 0 {
+		panic("interval and burst must be positive")
+	}
+	return &RateLimiter{interval: interval, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Acquire returns a Promise that fulfills once a token is available. The
+// token is spent even if nobody awaits the promise.
+func (rl *RateLimiter) Acquire() *Promise[struct{}] {
+	wait := rl.reserve()
+	p := newPromise[struct{}]()
+	if wait <= 0 {
+		p.resolve(struct{}{})
+		return p
+	}
+	time.AfterFunc(wait, func() { p.resolve(struct{}{}) })
+	return p
+}
+
+// reserve takes a token, possibly one that is not there yet, and returns how
+// long to wait until it is.
+func (rl *RateLimiter) reserve() time.Duration {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	now := time.Now()
+	rl.tokens += float64(now.Sub(rl.last)) / float64(rl.interval)
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+	rl.last = now
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-rl.tokens * float64(rl.interval))
+}
+
+// RateLimited wraps factory so that each call waits for a token from rl
+// before calling factory. The wait does not block the caller.
+func RateLimited[T any](rl *RateLimiter, factory func() *Promise[T]) func() *Promise[T] {
+	if rl == nil || factory == nil {
+		panic("rate limiter and factory cannot be nil")
+	}
+	return func() *Promise[T] {
+		token := rl.Acquire()
+		return derive(func(resolve func(T), reject func(error)) {
+			token.await()
+			val, err := orReject(factory()).await()
+			if err != nil {
+				reject(err)
+				return
+			}
+			resolve(val)
+		}, token)
+	}
+}
@@ -0,0 +1,40 @@
+package gopromise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rl := NewRateLimiter(20*time.Millisecond, 2)
+	start := time.Now()
+	for idx := 0; idx < 4; idx++ {
+		_, err := rl.Acquire().Await()
+		assertNotErr(t, err)
+	}
+	elapsed := time.Since(start)
+	assert(t, elapsed >= 35*time.Millisecond, "the tokens beyond the burst should wait, took", elapsed.String())
+}
+
+func TestRateLimited(t *testing.T) {
+	rl := NewRateLimiter(time.Hour, 1)
+	calls := 0
+	fetch := RateLimited(rl, func() *Promise[int] {
+		calls++
+		return Resolve(calls)
+	})
+
+	res, err := fetch().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+
+	_, err = fetch().AwaitTimeout(20 * time.Millisecond)
+	assertEqual(t, ErrAwaitTimeout, err)
+	assertEqual(t, 1, calls)
+}
+
+func TestRateLimited_NilPromise(t *testing.T) {
+	call := RateLimited(NewRateLimiter(time.Millisecond, 1), func() *Promise[int] { return nil })
+	_, err := call().Await()
+	assertEqual(t, ErrNilPromise, err)
+}
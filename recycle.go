@@ -0,0 +1,61 @@
+package gopromise
+
+import (
+	"sync"
+	"time"
+)
+
+// Recycler hands out promises backed by a sync.Pool, for servers that create
+// and settle very many short-lived promises and want to spare the garbage
+// collector the work.
+//
+// A recycled promise has a clear end of life: once it has settled and every
+// reader is done with it, its owner passes it to Release, and from then on
+// neither the promise nor its resolve and reject functions may be used. In
+// particular, nothing may still await it, hold on to its Done channel, or
+// have derived promises from it that have yet to run.
+type Recycler[T any] struct {
+	pool sync.Pool
+}
+
+// pooled is an entry of a Recycler: a promise together with its resolve and
+// reject functions, which stay bound to it across reuses.
+type pooled[T any] struct {
+	promise Promise[T]
+	resolve func(T)
+	reject  func(error)
+}
+
+// NewRecycler returns an empty Recycler.
+func NewRecycler[T any]() *Recycler[T] {
+	r := &Recycler[T]{}
+	r.pool.New = func() any {
+		e := &pooled[T]{}
+		e.resolve = e.promise.resolve
+		e.reject = e.promise.reject
+		return e
+	}
+	return r
+}
+
+// Deferred is like the Deferred function but takes the promise from r.
+// Recycled promises take no options: a timeout or context could otherwise
+// settle a promise after it has been released and reused.
+func (r *Recycler[T]) Deferred() (p *Promise[T], resolve func(T), reject func(error)) {
+	e := r.pool.Get().(*pooled[T])
+	e.promise = Promise[T]{done: make(chan struct{}), created: time.Now(), pooled: e}
+	e.promise.notifyCreated()
+	return &e.promise, e.resolve, e.reject
+}
+
+// Release returns p to r for reuse. It panics when p is still pending or
+// was not obtained from a Recycler.
+func (r *Recycler[T]) Release(p *Promise[T]) {
+	if p.pooled == nil {
+		panic("promise was not obtained from a Recycler")
+	}
+	if p.State() == Pending {
+		panic("cannot release a pending promise")
+	}
+	r.pool.Put(p.pooled)
+}
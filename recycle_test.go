@@ -0,0 +1,41 @@
+package gopromise
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRecycler(t *testing.T) {
+	r := NewRecycler[int]()
+	for i := 0; i < 3; i++ {
+		p, resolve, reject := r.Deferred()
+		assert(t, p.IsPending(), "recycled promise should start pending")
+		if i%2 == 0 {
+			resolve(i)
+			val, err := p.Await()
+			assertNotErr(t, err)
+			assertEqual(t, i, val)
+		} else {
+			reject(promiseError)
+			_, err := p.Await()
+			assert(t, errors.Is(err, promiseError))
+		}
+		r.Release(p)
+	}
+}
+
+func TestRecycler_ReleasePending(t *testing.T) {
+	r := NewRecycler[int]()
+	p, _, _ := r.Deferred()
+	defer func() {
+		assertNotNil(t, recover())
+	}()
+	r.Release(p)
+}
+
+func TestRecycler_ReleaseForeign(t *testing.T) {
+	defer func() {
+		assertNotNil(t, recover())
+	}()
+	NewRecycler[int]().Release(Resolve(1))
+}
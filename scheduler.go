@@ -0,0 +1,243 @@
+package gopromise
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Scheduler runs the executors of promises. Schedule must eventually call
+// task exactly once, or return an error, in which case the promise rejects
+// with that error without its executor running.
+type Scheduler interface {
+	Schedule(task func()) error
+}
+
+var (
+	// ErrSchedulerFull is the rejection reason of a promise whose executor
+	// could not be queued because the scheduler was full.
+	ErrSchedulerFull = errors.New("scheduler queue full")
+	// ErrSchedulerClosed is the rejection reason of a promise whose executor
+	// was handed to a scheduler after it was closed.
+	ErrSchedulerClosed = errors.New("scheduler closed")
+)
+
+// FullPolicy tells a WorkerPoolScheduler what to do with a task when its
+// queue is full.
+type FullPolicy int
+
+const (
+	// RejectWhenFull refuses the task, rejecting its promise with
+	// ErrSchedulerFull.
+	RejectWhenFull FullPolicy = iota
+	// BlockWhenFull makes the caller wait until the queue has room.
+	BlockWhenFull
+	// RunWhenFull runs the task on the caller's goroutine.
+	RunWhenFull
+)
+
+// Priority orders the tasks waiting in a PriorityScheduler: tasks of a
+// higher priority run first, tasks of the same priority in the order they
+// were scheduled.
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// PriorityScheduler is a Scheduler that can run some tasks ahead of others.
+// Promises created WithPriority on a PriorityScheduler are scheduled through
+// SchedulePriority; the others through Schedule, at PriorityNormal.
+type PriorityScheduler interface {
+	Scheduler
+	SchedulePriority(task func(), priority Priority) error
+}
+
+// WithPriority sets the priority the executor of the promise is scheduled
+// with, when its Scheduler is a PriorityScheduler. Other schedulers ignore it.
+func WithPriority(priority Priority) Option {
+	return func(o *options) {
+		o.priority = priority
+	}
+}
+
+// WorkerPoolScheduler is a PriorityScheduler that runs tasks on a fixed set
+// of goroutines fed by a bounded queue.
+//
+// An executor that blocks on another promise run by the same pool holds its
+// worker while it waits, so a pool smaller than the depth of such nesting can
+// deadlock.
+type WorkerPoolScheduler struct {
+	mutex     sync.Mutex
+	work      *sync.Cond
+	room      *sync.Cond
+	queues    [PriorityHigh - PriorityLow + 1][]func()
+	queued    int
+	queueSize int
+	idle      int
+	policy    FullPolicy
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// PoolScheduler starts workers goroutines that run the tasks scheduled on the
+// returned scheduler, queueing up to queueSize tasks while they are all busy.
+// policy tells what happens to tasks once the queue is full.
+func PoolScheduler(workers, queueSize int, policy FullPolicy) *WorkerPoolScheduler {
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	s := &WorkerPoolScheduler{queueSize: queueSize, policy: policy}
+	s.work = sync.NewCond(&s.mutex)
+	s.room = sync.NewCond(&s.mutex)
+	s.wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go s.worker()
+	}
+	return s
+}
+
+// Schedule is SchedulePriority at PriorityNormal.
+func (s *WorkerPoolScheduler) Schedule(task func()) error {
+	return s.SchedulePriority(task, PriorityNormal)
+}
+
+// SchedulePriority queues task ahead of the tasks of lower priority, applying
+// the scheduler's FullPolicy when the queue is full. It returns
+// ErrSchedulerClosed once Close has been called.
+func (s *WorkerPoolScheduler) SchedulePriority(task func(), priority Priority) error {
+	if priority < PriorityLow {
+		priority = PriorityLow
+	} else if priority > PriorityHigh {
+		priority = PriorityHigh
+	}
+
+	s.mutex.Lock()
+	for {
+		if s.closed {
+			s.mutex.Unlock()
+			return ErrSchedulerClosed
+		}
+		// Idle workers take tasks right away, so they count as room.
+		if s.queued < s.queueSize+s.idle {
+			break
+		}
+		switch s.policy {
+		case BlockWhenFull:
+			s.room.Wait()
+			continue
+		case RunWhenFull:
+			s.mutex.Unlock()
+			task()
+			return nil
+		}
+		s.mutex.Unlock()
+		return ErrSchedulerFull
+	}
+
+	idx := priority - PriorityLow
+	s.queues[idx] = append(s.queues[idx], task)
+	s.queued++
+	if s.idle > 0 {
+		s.idle--
+		s.work.Signal()
+	}
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *WorkerPoolScheduler) worker() {
+	defer s.wg.Done()
+	s.mutex.Lock()
+	for {
+		if task := s.pop(); task != nil {
+			s.mutex.Unlock()
+			task()
+			s.mutex.Lock()
+			continue
+		}
+		if s.closed {
+			s.mutex.Unlock()
+			return
+		}
+		// Whoever wakes this worker up takes it off the idle count.
+		s.idle++
+		s.room.Signal()
+		s.work.Wait()
+	}
+}
+
+// pop removes the next task to run from the queue, or returns nil when it is
+// empty.
+func (s *WorkerPoolScheduler) pop() func() {
+	for idx := len(s.queues) - 1; idx >= 0; idx-- {
+		if q := s.queues[idx]; len(q) > 0 {
+			task := q[0]
+			q[0] = nil
+			s.queues[idx] = q[1:]
+			s.queued--
+			s.room.Signal()
+			return task
+		}
+	}
+	return nil
+}
+
+// Close stops accepting tasks and waits for the workers to run the tasks
+// already queued.
+func (s *WorkerPoolScheduler) Close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.idle = 0
+	s.work.Broadcast()
+	s.room.Broadcast()
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
+
+// Submitter is implemented by goroutine pools that run submitted tasks, such
+// as ants.Pool. Submit returns an error when the pool refuses the task.
+type Submitter interface {
+	Submit(task func()) error
+}
+
+// SubmitterFunc adapts a function to Submitter, e.g. to plug in a pool whose
+// Submit method does not return an error:
+//
+//	wp := workerpool.New(8)
+//	sched := FromSubmitter(SubmitterFunc(func(task func()) error {
+//		wp.Submit(task)
+//		return nil
+//	}))
+type SubmitterFunc func(task func()) error
+
+// Submit calls f(task).
+func (f SubmitterFunc) Submit(task func()) error {
+	return f(task)
+}
+
+type submitScheduler struct {
+	pool Submitter
+}
+
+// FromSubmitter returns a Scheduler that hands tasks to pool. When pool
+// refuses a task, the promise rejects with an error that matches both
+// ErrSchedulerFull and the pool's own error under errors.Is.
+func FromSubmitter(pool Submitter) Scheduler {
+	if pool == nil {
+		panic("pool cannot be nil")
+	}
+	return submitScheduler{pool: pool}
+}
+
+func (s submitScheduler) Schedule(task func()) error {
+	if err := s.pool.Submit(task); err != nil {
+		return fmt.Errorf("%w: %w", ErrSchedulerFull, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package gopromise
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPoolScheduler_RejectWhenFull(t *testing.T) {
+	pool := PoolScheduler(1, 1, RejectWhenFull)
+	defer pool.Close()
+
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	blocked := New(func(resolve func(int), reject func(error)) {
+		close(started)
+		<-gate
+		resolve(1)
+	}, WithScheduler(pool))
+	<-started
+	queued := New(func(resolve func(int), reject func(error)) { resolve(2) }, WithScheduler(pool))
+	refused := New(func(resolve func(int), reject func(error)) { resolve(3) }, WithScheduler(pool))
+
+	_, err := refused.Await()
+	assertEqual(t, ErrSchedulerFull, err)
+
+	close(gate)
+	res, err := blocked.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+	res, err = queued.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+}
+
+func TestPoolScheduler_BlockWhenFull(t *testing.T) {
+	pool := PoolScheduler(2, 0, BlockWhenFull)
+	var sum int32
+	promises := make([]*Promise[int], 20)
+	for idx := range promises {
+		idx := idx
+		promises[idx] = New(func(resolve func(int), reject func(error)) {
+			atomic.AddInt32(&sum, int32(idx))
+			resolve(idx)
+		}, WithScheduler(pool))
+	}
+
+	_, err := All(promises...).Await()
+	assertNotErr(t, err)
+	assertEqual(t, int32(190), atomic.LoadInt32(&sum))
+
+	pool.Close()
+	_, err = New(func(resolve func(int), reject func(error)) { resolve(1) }, WithScheduler(pool)).Await()
+	assertEqual(t, ErrSchedulerClosed, err)
+}
+
+func TestPoolScheduler_RunWhenFull(t *testing.T) {
+	pool := PoolScheduler(1, 1, RunWhenFull)
+	defer pool.Close()
+
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	blocked := New(func(resolve func(int), reject func(error)) {
+		close(started)
+		<-gate
+		resolve(1)
+	}, WithScheduler(pool))
+	<-started
+	queued := New(func(resolve func(int), reject func(error)) { resolve(0) }, WithScheduler(pool))
+
+	ranInline := false
+	res, err := New(func(resolve func(int), reject func(error)) {
+		ranInline = true
+		resolve(2)
+	}, WithScheduler(pool)).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res)
+	assert(t, ranInline, "the task should have run on the caller's goroutine")
+
+	close(gate)
+	blocked.Await()
+	queued.Await()
+}
+
+type overloadedPool struct{}
+
+var errOverload = errors.New("pool overloaded")
+
+func (overloadedPool) Submit(func()) error {
+	return errOverload
+}
+
+func TestFromSubmitter(t *testing.T) {
+	var submitted int32
+	sched := FromSubmitter(SubmitterFunc(func(task func()) error {
+		atomic.AddInt32(&submitted, 1)
+		go task()
+		return nil
+	}))
+	res, err := New(func(resolve func(int), reject func(error)) { resolve(1) }, WithScheduler(sched)).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res)
+	assertEqual(t, int32(1), atomic.LoadInt32(&submitted))
+
+	_, err = New(func(resolve func(int), reject func(error)) { resolve(1) }, WithScheduler(FromSubmitter(overloadedPool{}))).Await()
+	assert(t, errors.Is(err, ErrSchedulerFull) && errors.Is(err, errOverload), "expected both the pool's error and ErrSchedulerFull")
+}
+
+func TestPoolScheduler_Priority(t *testing.T) {
+	pool := PoolScheduler(1, 10, RejectWhenFull)
+	defer pool.Close()
+
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	blocked := New(func(resolve func(int), reject func(error)) {
+		close(started)
+		<-gate
+		resolve(0)
+	}, WithScheduler(pool))
+	<-started
+
+	order := make(chan Priority, 3)
+	var promises []*Promise[int]
+	for _, priority := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		priority := priority
+		promises = append(promises, New(func(resolve func(int), reject func(error)) {
+			order <- priority
+			resolve(0)
+		}, WithScheduler(pool), WithPriority(priority)))
+	}
+	close(gate)
+	blocked.Await()
+	All(promises...).Await()
+
+	assertEqual(t, PriorityHigh, <-order)
+	assertEqual(t, PriorityNormal, <-order)
+	assertEqual(t, PriorityLow, <-order)
+}
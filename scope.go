@@ -0,0 +1,119 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrScopeClosed is the rejection reason of promises spawned into a Scope
+// whose Wait has already returned.
+var ErrScopeClosed = errors.New("scope closed")
+
+// Scope gives promises a structured lifetime, like a nursery: children are
+// spawned into the scope, the first child to fail cancels its siblings, and
+// Wait does not return before every child has settled and its function, if
+// it started, has returned. No child outlives the scope.
+type Scope struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	mutex  sync.Mutex
+	errs   []error
+	closed bool
+}
+
+// NewScope returns a Scope whose children are cancelled when ctx is done.
+func NewScope(ctx context.Context) *Scope {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Scope{ctx: ctx, cancel: cancel}
+}
+
+// Spawn runs fn as a child of s and returns its promise. The context given to
+// fn is cancelled when s is cancelled, when a sibling fails, or when Wait
+// returns. A child that rejects, including by panicking, fails s.
+func Spawn[T any](s *Scope, fn func(ctx context.Context) (T, error)) *Promise[T] {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return Reject[T](ErrScopeClosed)
+	}
+	s.wg.Add(1)
+	s.mutex.Unlock()
+
+	// The child is done once it has settled and fn has returned, or once it
+	// has settled without its executor starting, e.g. because the scheduler
+	// refused it, in which case fn never runs.
+	var state atomic.Int32
+	var holds atomic.Int32
+	holds.Store(2)
+	release := func() {
+		if holds.Add(-1) == 0 {
+			s.wg.Done()
+		}
+	}
+	p := NewWithContext(func(ctx context.Context, resolve func(T), reject func(error)) {
+		if !state.CompareAndSwap(childIdle, childRunning) {
+			return
+		}
+		defer release()
+		val, err := fn(ctx)
+		if err != nil {
+			reject(err)
+			return
+		}
+		resolve(val)
+	}, WithContext(s.ctx))
+	if p.State() != Pending && state.CompareAndSwap(childIdle, childSkipped) {
+		release()
+	}
+	p.whenSettled(func() {
+		// Children cancelled because s was cancelled did not fail.
+		if p.reason != nil && (p.State() != Cancelled || s.ctx.Err() == nil) {
+			s.fail(p.reason)
+		}
+		release()
+	})
+	return p
+}
+
+const (
+	childIdle = iota
+	childRunning
+	childSkipped
+)
+
+// fail records err and cancels the remaining children. Errors caused by the
+// scope's own cancellation are not recorded.
+func (s *Scope) fail(err error) {
+	if s.ctx.Err() != nil && errors.Is(err, context.Canceled) {
+		return
+	}
+	s.mutex.Lock()
+	s.errs = append(s.errs, err)
+	s.mutex.Unlock()
+	s.cancel(err)
+}
+
+// Cancel cancels every child of s with cause.
+func (s *Scope) Cancel(cause error) {
+	s.cancel(cause)
+}
+
+// Context returns the context shared by the children of s.
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// Wait blocks until every child is done, then closes s and returns the
+// errors the children failed with, joined, or nil.
+func (s *Scope) Wait() error {
+	s.wg.Wait()
+	s.mutex.Lock()
+	s.closed = true
+	errs := s.errs
+	s.mutex.Unlock()
+	s.cancel(ErrScopeClosed)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScope(t *testing.T) {
+	s := NewScope(context.Background())
+	a := Spawn(s, func(ctx context.Context) (int, error) { return 1, nil })
+	b := Spawn(s, func(ctx context.Context) (string, error) { return "b", nil })
+
+	assertNil(t, s.Wait())
+	res, _ := a.Await()
+	assertEqual(t, 1, res)
+	str, _ := b.Await()
+	assertEqual(t, "b", str)
+
+	_, err := Spawn(s, func(ctx context.Context) (int, error) { return 0, nil }).Await()
+	assertEqual(t, ErrScopeClosed, err)
+}
+
+func TestScope_FailureCancelsSiblings(t *testing.T) {
+	var finished int32
+	s := NewScope(context.Background())
+	Spawn(s, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+		return 0, ctx.Err()
+	})
+	Spawn(s, func(ctx context.Context) (int, error) {
+		return 0, promiseError
+	})
+
+	err := s.Wait()
+	assert(t, errors.Is(err, promiseError))
+	assert(t, !errors.Is(err, context.Canceled), "sibling cancellation should not be reported")
+	assertEqual(t, int32(1), atomic.LoadInt32(&finished), "Wait returned before a child finished")
+}
+
+func TestScope_RefusedChild(t *testing.T) {
+	s := NewScope(context.Background())
+	refusing := &countingScheduler{err: ErrSchedulerFull}
+	SetDefaults(Config{Scheduler: refusing})
+	_, err := Spawn(s, func(ctx context.Context) (int, error) { return 1, nil }).Await()
+	SetDefaults(Config{})
+	assertEqual(t, ErrSchedulerFull, err)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Wait() }()
+	select {
+	case err := <-done:
+		assert(t, errors.Is(err, ErrSchedulerFull))
+	case <-time.After(time.Second):
+		t.Fatal("Wait should return once a refused child has settled")
+	}
+}
+
+func TestScope_PanicFailsScope(t *testing.T) {
+	var cancelled atomic.Bool
+	s := NewScope(context.Background())
+	Spawn(s, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		cancelled.Store(true)
+		return 0, ctx.Err()
+	})
+	Spawn(s, func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+
+	err := s.Wait()
+	var panicErr *PanicError
+	assert(t, errors.As(err, &panicErr), "a panicking child should fail the scope")
+	assert(t, cancelled.Load(), "siblings of a panicking child should be cancelled")
+}
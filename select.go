@@ -0,0 +1,58 @@
+package gopromise
+
+// SelectCase is one case of Select: a promise of any type together with the
+// callback to run should it be the first to settle. Cases are built with
+// Case.
+type SelectCase struct {
+	src         source
+	whenSettled func(fn func())
+	fire        func()
+}
+
+// Case returns a SelectCase for p. cb, which may be nil, receives the outcome
+// of p when p is the case Select picks. A nil p is treated as a promise
+// rejected with ErrNilPromise.
+func Case[T any](p *Promise[T], cb func(val T, err error)) SelectCase {
+	if p == nil {
+		p = Reject[T](ErrNilPromise)
+	}
+	return SelectCase{
+		src:         p,
+		whenSettled: p.whenSettled,
+		fire: func() {
+			if cb != nil {
+				cb(p.await())
+			}
+		},
+	}
+}
+
+// Select waits for the first of cases to settle, runs its callback and
+// fulfills with its index, the way a select statement picks among channels
+// of different types. It rejects with ErrNoPromises when there are no cases,
+// and with the panic of the callback should it panic.
+//
+// Once a case has been picked, Select gives up its claim on the others the
+// same way RaceWith does, with ErrRaceLost as their cause.
+func Select(cases ...SelectCase) *Promise[int] {
+	if len(cases) == 0 {
+		return Reject[int](ErrNoPromises)
+	}
+	upstream := make([]source, len(cases))
+	for idx, c := range cases {
+		upstream[idx] = c.src
+	}
+	sel := newDerived[int](upstream...)
+	sel.start(func(resolve func(int), reject func(error)) {
+		picked := make(chan int, len(cases))
+		for idx, c := range cases {
+			idx := idx
+			c.whenSettled(func() { picked <- idx })
+		}
+		idx := <-picked
+		sel.releaseUpstream(ErrRaceLost)
+		cases[idx].fire()
+		resolve(idx)
+	}, nil)
+	return sel
+}
@@ -0,0 +1,24 @@
+package gopromise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSelect(t *testing.T) {
+	var user string
+	idx, err := Select(
+		Case(sleepy(time.Second, 1, nil)(), func(int, error) { t.Error("slow case fired") }),
+		Case(sleepy(5*time.Millisecond, "ada", nil)(), func(val string, err error) { user = val }),
+	).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, idx)
+	assertEqual(t, "ada", user)
+
+	idx, err = Select(Case[int](Reject[int](promiseError), nil)).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 0, idx)
+
+	_, err = Select().Await()
+	assertEqual(t, ErrNoPromises, err)
+}
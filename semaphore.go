@@ -0,0 +1,230 @@
+package gopromise
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// Semaphore is a weighted semaphore. Waiters are served in the order they
+// arrived, so a large request is not starved by a stream of small ones.
+type Semaphore struct {
+	mutex   sync.Mutex
+	size    int64
+	cur     int64
+	waiters list.List
+}
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewSemaphore returns a Semaphore with a total weight of size.
+func NewSemaphore(size int64) *Semaphore {
+	if size <= 0 {
+		panic("semaphore size must be positive")
+	}
+	return &Semaphore{size: size}
+}
+
+// Acquire blocks until a weight of n is available or ctx is done, in which
+// case it returns ctx.Err() and acquires nothing.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if s.acquire(n, ctx.Done()) {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// TryAcquire acquires a weight of n without blocking and reports whether it
+// did.
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release gives back a weight of n.
+func (s *Semaphore) Release(n int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("semaphore released more than held")
+	}
+	s.notify()
+}
+
+// acquire blocks until a weight of n is available or cancel is closed, and
+// reports whether it acquired the weight.
+func (s *Semaphore) acquire(n int64, cancel <-chan struct{}) bool {
+	select {
+	case <-cancel:
+		return false
+	default:
+	}
+	s.mutex.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mutex.Unlock()
+		return true
+	}
+	if n > s.size {
+		// Never satisfiable: wait for cancel without blocking the others.
+		s.mutex.Unlock()
+		<-cancel
+		return false
+	}
+	w := semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+		select {
+		case <-cancel:
+			s.Release(n)
+			return false
+		default:
+			return true
+		}
+	case <-cancel:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		select {
+		case <-w.ready:
+			// Acquired while being cancelled; give it back.
+			s.cur -= n
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if !front {
+				return false
+			}
+		}
+		s.notify()
+		return false
+	}
+}
+
+// notify wakes the waiters at the front of the queue that now fit.
+func (s *Semaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(semWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// NewWithSemaphore is like New but starts exec only once it has acquired a
+// weight of one from sem, and releases it as the promise settles, before any
+// waiter sees the outcome. Waiting for sem does not block the caller. A
+// promise that settles while still waiting, e.g. because it was cancelled,
+// never runs exec. It honours the same options as New.
+func NewWithSemaphore[T any](sem *Semaphore, exec func(resolve func(T), reject func(error)), opts ...Option) *Promise[T] {
+	if sem == nil || exec == nil {
+		panic("semaphore and executor cannot be nil")
+	}
+	o := buildOptions(opts)
+	p := newNamedPromise[T](o.name)
+	p.start(func(resolve func(T), reject func(error)) {
+		if !sem.acquire(1, p.done) {
+			return
+		}
+		p.mutex.Lock()
+		if p.State() != Pending {
+			p.mutex.Unlock()
+			sem.Release(1)
+			return
+		}
+		p.onSettle = func() { sem.Release(1) }
+		p.mutex.Unlock()
+		exec(resolve, reject)
+	}, o)
+	p.enforce(o)
+	return p
+}
+
+// Releaser gives back the weight acquired from an AsyncSemaphore. Only its
+// first call to Release has an effect.
+type Releaser interface {
+	Release()
+}
+
+type releaseFunc func()
+
+func (f releaseFunc) Release() {
+	f()
+}
+
+// AsyncSemaphore is a weighted semaphore acquired through promises, so that
+// waiting for a resource budget can be combined with other promises, e.g.
+// given up with AwaitTimeout or raced against a cancellation.
+type AsyncSemaphore struct {
+	sem *Semaphore
+}
+
+// NewAsyncSemaphore returns an AsyncSemaphore with a total weight of n.
+func NewAsyncSemaphore(n int64) *AsyncSemaphore {
+	return &AsyncSemaphore{sem: NewSemaphore(n)}
+}
+
+// Acquire returns a Promise fulfilled with a Releaser once a weight of
+// weight has been acquired. Waiters are served in the order they called
+// Acquire. Cancelling the promise before it fulfills withdraws the request.
+func (s *AsyncSemaphore) Acquire(weight int64) *Promise[Releaser] {
+	return acquireAsync(s.sem, weight, func(release func()) Releaser { return releaseFunc(release) })
+}
+
+// TryAcquire acquires a weight of weight without waiting and reports whether
+// it did.
+func (s *AsyncSemaphore) TryAcquire(weight int64) (Releaser, bool) {
+	if !s.sem.TryAcquire(weight) {
+		return nil, false
+	}
+	return releaseFunc(s.sem.releaser(weight)), true
+}
+
+// acquireAsync returns a Promise fulfilled with wrap(release) once a weight
+// of n has been acquired from s, where release gives the weight back and
+// does nothing after its first call. A promise that settles otherwise, e.g.
+// because it was cancelled, acquires nothing.
+func acquireAsync[R any](s *Semaphore, n int64, wrap func(release func()) R) *Promise[R] {
+	p := newPromise[R]()
+	grant := func() {
+		release := s.releaser(n)
+		if !p.settle(Fulfilled, wrap(release), nil, false) {
+			release()
+		}
+	}
+	if s.TryAcquire(n) {
+		grant()
+		return p
+	}
+	go func() {
+		if s.acquire(n, p.done) {
+			grant()
+		}
+	}()
+	return p
+}
+
+// releaser returns a function that gives back a weight of n to s on its
+// first call and does nothing afterward.
+func (s *Semaphore) releaser(n int64) func() {
+	var once sync.Once
+	return func() { once.Do(func() { s.Release(n) }) }
+}
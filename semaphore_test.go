@@ -0,0 +1,95 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	sem := NewSemaphore(3)
+	assert(t, sem.TryAcquire(2))
+	assert(t, !sem.TryAcquire(2), "only a weight of 1 should be left")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assertEqual(t, context.DeadlineExceeded, sem.Acquire(ctx, 2))
+
+	acquired := make(chan struct{})
+	go func() {
+		sem.Acquire(context.Background(), 3)
+		close(acquired)
+	}()
+	sem.Release(2)
+	<-acquired
+	assert(t, !sem.TryAcquire(1))
+	sem.Release(3)
+	assert(t, sem.TryAcquire(3))
+}
+
+func TestNewWithSemaphore(t *testing.T) {
+	sem := NewSemaphore(2)
+	var running, peak int32
+	promises := make([]*Promise[int], 6)
+	for idx := range promises {
+		idx := idx
+		promises[idx] = NewWithSemaphore(sem, func(resolve func(int), reject func(error)) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			resolve(idx)
+		})
+	}
+
+	_, err := All(promises...).Await()
+	assertNotErr(t, err)
+	assert(t, atomic.LoadInt32(&peak) <= 2, "at most 2 executors should run at a time")
+	assert(t, sem.TryAcquire(2), "every slot should have been released")
+}
+
+func TestNewWithSemaphore_CancelledWhileWaiting(t *testing.T) {
+	sem := NewSemaphore(1)
+	assert(t, sem.TryAcquire(1))
+
+	ran := false
+	p := NewWithSemaphore(sem, func(resolve func(int), reject func(error)) {
+		ran = true
+		resolve(1)
+	})
+	p.Cancel(nil)
+	_, err := p.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+
+	sem.Release(1)
+	assert(t, sem.TryAcquire(1), "the cancelled promise should not hold a slot")
+	assert(t, !ran, "a promise cancelled while waiting should never run")
+}
+
+func TestAsyncSemaphore(t *testing.T) {
+	sem := NewAsyncSemaphore(10)
+	big, err := sem.Acquire(8).Await()
+	assertNotErr(t, err)
+	_, ok := sem.TryAcquire(3)
+	assert(t, !ok, "only a weight of 2 should be left")
+
+	waiting := sem.Acquire(5)
+	_, err = waiting.AwaitTimeout(10 * time.Millisecond)
+	assertEqual(t, ErrAwaitTimeout, err)
+
+	big.Release()
+	big.Release()
+	r, err := waiting.Await()
+	assertNotErr(t, err)
+	small, ok := sem.TryAcquire(5)
+	assert(t, ok, "a weight of 5 should be left")
+	r.Release()
+	small.Release()
+}
@@ -0,0 +1,129 @@
+package gopromise
+
+import "sync/atomic"
+
+// Settlement is the outcome of a settled promise: its value when Fulfilled,
+// its rejection reason otherwise. Index is the position of the promise among
+// the inputs of the combinator that produced the Settlement.
+type Settlement[T any] struct {
+	Value     T
+	Err       error
+	Fulfilled bool
+	Index     int
+}
+
+// FromSettlement returns a Promise already settled with the outcome s
+// describes.
+func FromSettlement[T any](s Settlement[T]) *Promise[T] {
+	if s.Fulfilled {
+		return Resolve(s.Value)
+	}
+	return Reject[T](s.Err)
+}
+
+func settlementOf[T any](idx int, val T, err error) Settlement[T] {
+	if err != nil {
+		return Settlement[T]{Err: err, Index: idx}
+	}
+	return Settlement[T]{Value: val, Fulfilled: true, Index: idx}
+}
+
+// AllSettled returns a Promise that fulfills with the settlements of
+// promises, in order, once all of them have settled. It never rejects.
+func AllSettled[T any](promises ...*Promise[T]) *Promise[[]Settlement[T]] {
+	promises = orRejected(promises)
+	return derive(func(resolve func([]Settlement[T]), reject func(error)) {
+		settlements := make([]Settlement[T], len(promises))
+		for idx, p := range promises {
+			val, err := p.await()
+			settlements[idx] = settlementOf(idx, val, err)
+		}
+		resolve(settlements)
+	}, sources(promises)...)
+}
+
+// AwaitMany blocks until every one of promises has settled and returns
+// their values and rejection reasons, aligned with promises. Unlike All, it
+// never stops at the first rejection.
+func AwaitMany[T any](promises ...*Promise[T]) ([]T, []error) {
+	promises = orRejected(promises)
+	values := make([]T, len(promises))
+	errs := make([]error, len(promises))
+	for idx, p := range promises {
+		values[idx], errs[idx] = p.await()
+	}
+	return values, errs
+}
+
+// Partitioned holds the outcomes of promises split into the values of the
+// ones that fulfilled and the rejection reasons of the others, each in input
+// order.
+type Partitioned[T any] struct {
+	Fulfilled []T
+	Rejected  []IndexedError
+}
+
+// Partition returns a Promise that fulfills with the outcomes of promises,
+// split by Partitioned, once all of them have settled. It never rejects.
+func Partition[T any](promises ...*Promise[T]) *Promise[Partitioned[T]] {
+	promises = orRejected(promises)
+	return derive(func(resolve func(Partitioned[T]), reject func(error)) {
+		var parts Partitioned[T]
+		for idx, p := range promises {
+			val, err := p.await()
+			if err != nil {
+				parts.Rejected = append(parts.Rejected, IndexedError{Index: idx, Err: err})
+				continue
+			}
+			parts.Fulfilled = append(parts.Fulfilled, val)
+		}
+		resolve(parts)
+	}, sources(promises)...)
+}
+
+// AsCompleted returns a channel that receives the settlements of promises in
+// the order they settle, and is closed once all of them have been delivered.
+// Nothing waits on the promises on behalf of the channel, which is buffered,
+// so abandoning it leaks no goroutines.
+func AsCompleted[T any](promises ...*Promise[T]) <-chan Settlement[T] {
+	promises = orRejected(promises)
+	ch := make(chan Settlement[T], len(promises))
+	if len(promises) == 0 {
+		close(ch)
+		return ch
+	}
+	var remaining atomic.Int64
+	remaining.Store(int64(len(promises)))
+	for idx, p := range promises {
+		idx, p := idx, p
+		p.whenSettled(func() {
+			ch <- settlementOf(idx, p.value, p.reason)
+			if remaining.Add(-1) == 0 {
+				close(ch)
+			}
+		})
+	}
+	return ch
+}
+
+// RaceSettled returns a Promise that fulfills with the settlement of the
+// first of promises to settle, whether it fulfilled or rejected. It rejects
+// with ErrNoPromises when there are none. Like RaceWith, it gives up its
+// claim on the losing inputs with ErrRaceLost as their cause.
+func RaceSettled[T any](promises ...*Promise[T]) *Promise[Settlement[T]] {
+	promises = orRejected(promises)
+	if len(promises) == 0 {
+		return Reject[Settlement[T]](ErrNoPromises)
+	}
+	race := newDerived[Settlement[T]](sources(promises)...)
+	race.start(func(resolve func(Settlement[T]), reject func(error)) {
+		results := make(chan Settlement[T], len(promises))
+		for idx, p := range promises {
+			idx, p := idx, p
+			p.whenSettled(func() { results <- settlementOf(idx, p.value, p.reason) })
+		}
+		resolve(<-results)
+		race.releaseUpstream(ErrRaceLost)
+	}, nil)
+	return race
+}
@@ -0,0 +1,85 @@
+package gopromise
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestAllSettled(t *testing.T) {
+	res, err := AllSettled(Resolve(1), Reject[int](promiseError), sleepy(5*time.Millisecond, 3, nil)()).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, len(res))
+	assertEqual(t, Settlement[int]{Value: 1, Fulfilled: true, Index: 0}, res[0])
+	assertEqual(t, Settlement[int]{Err: promiseError, Index: 1}, res[1])
+	assertEqual(t, 3, res[2].Value)
+
+	val, err := FromSettlement(res[0]).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, val)
+	_, err = FromSettlement(res[1]).Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestRaceSettled(t *testing.T) {
+	res, err := RaceSettled(sleepy(time.Second, 1, nil)(), sleepy(5*time.Millisecond, 0, promiseError)()).Await()
+	assertNotErr(t, err)
+	assert(t, !res.Fulfilled)
+	assertEqual(t, 1, res.Index)
+	assertEqual(t, promiseError, res.Err)
+
+	_, err = RaceSettled[int]().Await()
+	assertEqual(t, ErrNoPromises, err)
+}
+
+func TestAwaitMany(t *testing.T) {
+	values, errs := AwaitMany(sleepy(5*time.Millisecond, 1, nil)(), Reject[int](promiseError), Resolve(3))
+	assertEqual(t, 3, len(values))
+	assertEqual(t, 1, values[0])
+	assertNil(t, errs[0])
+	assertEqual(t, promiseError, errs[1])
+	assertEqual(t, 3, values[2])
+}
+
+func TestPartition(t *testing.T) {
+	parts, err := Partition(Resolve(1), Reject[int](promiseError), sleepy(5*time.Millisecond, 3, nil)()).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, len(parts.Fulfilled))
+	assertEqual(t, 3, parts.Fulfilled[1])
+	assertEqual(t, 1, len(parts.Rejected))
+	assertEqual(t, 1, parts.Rejected[0].Index)
+	assert(t, errors.Is(parts.Rejected[0], promiseError))
+	assertEqual(t, "promise 1: Promise Error", parts.Rejected[0].Error())
+}
+
+func TestAsCompleted(t *testing.T) {
+	var order []int
+	for s := range AsCompleted(sleepy(20*time.Millisecond, 0, nil)(), Reject[int](promiseError), sleepy(5*time.Millisecond, 2, nil)()) {
+		order = append(order, s.Index)
+	}
+	assertEqual(t, "[1 2 0]", fmt.Sprint(order))
+}
+
+func TestAsCompleted_NoParkedGoroutines(t *testing.T) {
+	src, resolve, _ := Deferred[int]()
+	promises := make([]*Promise[int], 1000)
+	for idx := range promises {
+		promises[idx] = src
+	}
+	before := runtime.NumGoroutine()
+	ch := AsCompleted(promises...)
+	RaceSettled(promises...)
+	Quorum(2, promises...)
+	AnyWith(promises)
+	Coalesce(promises...)
+	assert(t, runtime.NumGoroutine() < before+100, "waiting on inputs should not park a goroutine each")
+
+	resolve(1)
+	count := 0
+	for range ch {
+		count++
+	}
+	assertEqual(t, 1000, count)
+}
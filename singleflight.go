@@ -0,0 +1,71 @@
+package gopromise
+
+import (
+	"errors"
+	"sync"
+)
+
+// SingleFlight deduplicates concurrent work by key: callers asking for a key
+// while its promise is in flight share that promise instead of starting the
+// work again. The zero value is ready to use.
+type SingleFlight[K comparable, T any] struct {
+	mutex sync.Mutex
+	calls map[K]*Promise[T]
+}
+
+// Do returns a Promise of the outcome of the work in flight for key, calling
+// factory to start it when there is none. The key is forgotten as soon as the
+// work settles, so the next call starts it anew.
+//
+// Each caller gets its own promise. Cancelling it does not affect the other
+// callers; once every caller has cancelled, the shared work is cancelled
+// too, provided its promise can be, as with Cancel.
+func (g *SingleFlight[K, T]) Do(key K, factory func() *Promise[T]) *Promise[T] {
+	if factory == nil {
+		panic("factory cannot be nil")
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	shared, ok := g.calls[key]
+	if !ok {
+		if g.calls == nil {
+			g.calls = make(map[K]*Promise[T])
+		}
+		shared = newDerived[T]()
+		shared.onSettle = func() { g.forget(key, shared) }
+		g.calls[key] = shared
+		shared.start(func(resolve func(T), reject func(error)) {
+			work := orReject(factory())
+			work.acquire()
+			select {
+			case <-work.Done():
+				val, err := work.await()
+				if err != nil {
+					reject(err)
+					return
+				}
+				resolve(val)
+			case <-shared.done:
+				// Every caller cancelled.
+				work.release(errors.Unwrap(shared.reason))
+			}
+		}, nil)
+	}
+	return follow(shared)
+}
+
+// Forget makes the next call to Do for key start new work, even if the
+// current work for key is still in flight.
+func (g *SingleFlight[K, T]) Forget(key K) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.calls, key)
+}
+
+func (g *SingleFlight[K, T]) forget(key K, p *Promise[T]) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.calls[key] == p {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,68 @@
+package gopromise
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight(t *testing.T) {
+	var g SingleFlight[string, int]
+	var calls int32
+	gate := make(chan struct{})
+	factory := func() *Promise[int] {
+		n := atomic.AddInt32(&calls, 1)
+		return New(func(resolve func(int), reject func(error)) {
+			<-gate
+			resolve(int(n))
+		})
+	}
+
+	p1 := g.Do("user:1", factory)
+	p2 := g.Do("user:1", factory)
+	close(gate)
+
+	res1, err := p1.Await()
+	assertNotErr(t, err)
+	res2, err := p2.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, res1)
+	assertEqual(t, 1, res2)
+
+	res, err := g.Do("user:1", factory).Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, res, "settled work should be forgotten")
+}
+
+func TestSingleFlight_CancelAllCallers(t *testing.T) {
+	var g SingleFlight[int, int]
+	stopped := make(chan struct{})
+	factory := func() *Promise[int] {
+		return NewWithContext(func(ctx context.Context, resolve func(int), reject func(error)) {
+			<-ctx.Done()
+			close(stopped)
+		})
+	}
+
+	p1 := g.Do(1, factory)
+	p2 := g.Do(1, factory)
+	p1.Cancel(nil)
+	select {
+	case <-stopped:
+		t.Fatal("work should keep running while a caller still waits")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	p2.Cancel(nil)
+	<-stopped
+	_, err := p2.Await()
+	assert(t, errors.Is(err, ErrCancelled))
+}
+
+func TestSingleFlight_NilPromise(t *testing.T) {
+	var g SingleFlight[string, int]
+	_, err := g.Do("key", func() *Promise[int] { return nil }).Await()
+	assertEqual(t, ErrNilPromise, err)
+}
@@ -0,0 +1,179 @@
+package gopromise
+
+import (
+	"sync"
+)
+
+// Stream is an asynchronous sequence of values that ends either in
+// completion or with an error. Where a Promise models a single value, a
+// Stream models many.
+//
+// A Stream has a single consumer: it is meant to be read once, by an
+// operator such as MapStream, Filter or Take, or by Collect. Values are
+// handed over one at a time, so a slow consumer slows the producer down.
+type Stream[T any] struct {
+	values   chan T
+	stop     chan struct{}
+	stopOnce sync.Once
+	// err is set before values is closed.
+	err error
+}
+
+func newStream[T any]() *Stream[T] {
+	return &Stream[T]{values: make(chan T), stop: make(chan struct{})}
+}
+
+// FromChannel returns a Stream of the values received from ch. The stream
+// completes once ch is closed.
+func FromChannel[T any](ch <-chan T) *Stream[T] {
+	s := newStream[T]()
+	go func() {
+		for {
+			select {
+			case val, ok := <-ch:
+				if !ok || !s.send(val) {
+					s.end(nil)
+					return
+				}
+			case <-s.stop:
+				s.end(nil)
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// Generate returns a Stream of the values gen emits. gen runs on its own
+// goroutine; emit blocks until the value is consumed and returns false once
+// the consumer has stopped reading, at which point gen should return. The
+// stream ends with gen's error, or completes when gen returns nil.
+func Generate[T any](gen func(emit func(T) bool) error) *Stream[T] {
+	s := newStream[T]()
+	go func() {
+		s.end(streamStep(func() error { return gen(s.send) }))
+	}()
+	return s
+}
+
+// MapStream returns a Stream of the results of fn applied to each value of
+// s. The stream ends with the first error fn returns.
+func MapStream[T, R any](s *Stream[T], fn func(T) (R, error)) *Stream[R] {
+	return pipe(s, func(val T, emit func(R) bool) (bool, error) {
+		res, err := fn(val)
+		if err != nil {
+			return false, err
+		}
+		return emit(res), nil
+	})
+}
+
+// Filter returns a Stream of the values of s that satisfy pred.
+func (s *Stream[T]) Filter(pred func(T) bool) *Stream[T] {
+	return pipe(s, func(val T, emit func(T) bool) (bool, error) {
+		return !pred(val) || emit(val), nil
+	})
+}
+
+// Take returns a Stream of the first n values of s. It stops reading s once
+// n values have been taken.
+func (s *Stream[T]) Take(n int) *Stream[T] {
+	if n <= 0 {
+		s.cancel()
+		out := newStream[T]()
+		out.end(nil)
+		return out
+	}
+	taken := 0
+	return pipe(s, func(val T, emit func(T) bool) (bool, error) {
+		taken++
+		return emit(val) && taken < n, nil
+	})
+}
+
+// Collect returns a Promise that fulfills with the values of s once it
+// completes, or rejects with the error s ends with.
+func (s *Stream[T]) Collect() *Promise[[]T] {
+	return run(func(resolve func([]T), reject func(error)) {
+		values := []T{}
+		for val := range s.values {
+			values = append(values, val)
+		}
+		if s.err != nil {
+			reject(s.err)
+			return
+		}
+		resolve(values)
+	})
+}
+
+// send hands val to the consumer. It reports false once the consumer has
+// stopped reading.
+func (s *Stream[T]) send(val T) bool {
+	select {
+	case s.values <- val:
+		return true
+	case <-s.stop:
+		return false
+	}
+}
+
+// end terminates s with err, or completes it when err is nil.
+func (s *Stream[T]) end(err error) {
+	s.err = err
+	close(s.values)
+}
+
+// cancel tells the producer of s that nobody reads it anymore.
+func (s *Stream[T]) cancel() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// pipe returns a Stream fed by step, which is called with each value of src
+// and may emit any number of values downstream. The stream stops reading src
+// when step reports false, and ends with the error step returns or panics
+// with.
+func pipe[T, R any](src *Stream[T], step func(val T, emit func(R) bool) (bool, error)) *Stream[R] {
+	out := newStream[R]()
+	go func() {
+		defer src.cancel()
+		for {
+			select {
+			case val, ok := <-src.values:
+				if !ok {
+					out.end(src.err)
+					return
+				}
+				var more bool
+				if err := streamStep(func() (err error) {
+					more, err = step(val, out.send)
+					return err
+				}); err != nil {
+					out.end(err)
+					return
+				}
+				if !more {
+					out.end(nil)
+					return
+				}
+			case <-out.stop:
+				out.end(nil)
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// streamStep runs fn, turning a panic into an error.
+func streamStep(fn func() error) (err error) {
+	returned := false
+	defer func() {
+		if !returned {
+			err = panicError(recover(), nil)
+		}
+	}()
+	err = fn()
+	returned = true
+	return err
+}
@@ -0,0 +1,59 @@
+package gopromise
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		for i := 1; i <= 10; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	even := FromChannel(ch).Filter(func(v int) bool { return v%2 == 0 })
+	strs := MapStream(even, func(v int) (string, error) { return strconv.Itoa(v), nil })
+	res, err := strs.Collect().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 5, len(res))
+	assertEqual(t, "10", res[4])
+}
+
+func TestStream_TakeStopsProducer(t *testing.T) {
+	stopped := make(chan struct{})
+	naturals := Generate(func(emit func(int) bool) error {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			if !emit(i) {
+				return nil
+			}
+		}
+	})
+
+	res, err := naturals.Take(3).Collect().Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, len(res))
+	assertEqual(t, 2, res[2])
+	<-stopped
+}
+
+func TestStream_Error(t *testing.T) {
+	s := Generate(func(emit func(int) bool) error {
+		emit(1)
+		return promiseError
+	})
+	_, err := s.Collect().Await()
+	assertEqual(t, promiseError, err)
+
+	parse := MapStream(Generate(func(emit func(string) bool) error {
+		emit("1")
+		emit("x")
+		emit("3")
+		return nil
+	}), func(s string) (int, error) { return strconv.Atoi(s) })
+	_, err = parse.Collect().Await()
+	assertErr(t, err)
+}
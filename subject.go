@@ -0,0 +1,187 @@
+package gopromise
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrSubjectCompleted is the rejection reason of Subject.Next once the
+// subject has completed.
+var ErrSubjectCompleted = errors.New("subject completed")
+
+// Subject emits values to many subscribers until it completes or fails.
+// Subscribers either wait for the next emission with Next or read every
+// emission through the Stream Subscribe returns.
+//
+// Emitting never blocks on subscribers: each Stream buffers the values its
+// consumer has not read yet.
+type Subject[T any] struct {
+	mutex   sync.Mutex
+	subs    map[uint64]*subjectSub[T]
+	nextSub uint64
+	waiters []*Promise[T]
+	ended   bool
+	err     error
+}
+
+type subjectSub[T any] struct {
+	stream *Stream[T]
+	mutex  sync.Mutex
+	queue  []T
+	ended  bool
+	err    error
+	wake   chan struct{}
+}
+
+// NewSubject returns a Subject with no subscribers.
+func NewSubject[T any]() *Subject[T] {
+	return &Subject[T]{subs: make(map[uint64]*subjectSub[T])}
+}
+
+// Emit delivers val to the current subscribers. It does nothing once s has
+// completed or failed.
+func (s *Subject[T]) Emit(val T) {
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	waiters := s.waiters
+	s.waiters = nil
+	for _, sub := range s.subs {
+		sub.push(val)
+	}
+	s.mutex.Unlock()
+
+	for _, p := range waiters {
+		p.resolve(val)
+	}
+}
+
+// Fail ends s with err: pending Next promises reject with err and subscriber
+// streams end with it.
+func (s *Subject[T]) Fail(err error) {
+	s.end(err)
+}
+
+// Complete ends s: pending Next promises reject with ErrSubjectCompleted and
+// subscriber streams complete.
+func (s *Subject[T]) Complete() {
+	s.end(nil)
+}
+
+func (s *Subject[T]) end(err error) {
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended, s.err = true, err
+	waiters := s.waiters
+	s.waiters = nil
+	for id, sub := range s.subs {
+		sub.finish(err)
+		delete(s.subs, id)
+	}
+	s.mutex.Unlock()
+
+	if err == nil {
+		err = ErrSubjectCompleted
+	}
+	for _, p := range waiters {
+		p.reject(err)
+	}
+}
+
+// Next returns a promise fulfilled with the next value s emits. It rejects
+// with the error s fails with, or with ErrSubjectCompleted once s completes.
+func (s *Subject[T]) Next() *Promise[T] {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ended {
+		if s.err != nil {
+			return Reject[T](s.err)
+		}
+		return Reject[T](ErrSubjectCompleted)
+	}
+	p := newPromise[T]()
+	s.waiters = append(s.waiters, p)
+	return p
+}
+
+// Subscribe returns a Stream of the values s emits from now on. The stream
+// ends when s fails or completes; once its consumer stops reading, the
+// subscription is dropped.
+func (s *Subject[T]) Subscribe() *Stream[T] {
+	sub := &subjectSub[T]{stream: newStream[T](), wake: make(chan struct{}, 1)}
+	unsubscribe := func() {}
+	s.mutex.Lock()
+	if s.ended {
+		sub.finish(s.err)
+	} else {
+		id := s.nextSub
+		s.nextSub++
+		s.subs[id] = sub
+		unsubscribe = func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			delete(s.subs, id)
+		}
+	}
+	s.mutex.Unlock()
+	go sub.pump(unsubscribe)
+	return sub.stream
+}
+
+func (sub *subjectSub[T]) push(val T) {
+	sub.mutex.Lock()
+	sub.queue = append(sub.queue, val)
+	sub.mutex.Unlock()
+	sub.signal()
+}
+
+func (sub *subjectSub[T]) finish(err error) {
+	sub.mutex.Lock()
+	sub.ended, sub.err = true, err
+	sub.mutex.Unlock()
+	sub.signal()
+}
+
+func (sub *subjectSub[T]) signal() {
+	select {
+	case sub.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump feeds the buffered values to the subscriber's stream, calling
+// unsubscribe once its consumer stops reading.
+func (sub *subjectSub[T]) pump(unsubscribe func()) {
+	for {
+		sub.mutex.Lock()
+		if len(sub.queue) == 0 {
+			ended, err := sub.ended, sub.err
+			sub.mutex.Unlock()
+			if ended {
+				sub.stream.end(err)
+				return
+			}
+			select {
+			case <-sub.wake:
+				continue
+			case <-sub.stream.stop:
+				unsubscribe()
+				sub.stream.end(nil)
+				return
+			}
+		}
+		val := sub.queue[0]
+		sub.queue = sub.queue[1:]
+		sub.mutex.Unlock()
+		if !sub.stream.send(val) {
+			unsubscribe()
+			sub.stream.end(nil)
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package gopromise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubject(t *testing.T) {
+	s := NewSubject[int]()
+	next := s.Next()
+	first, second := s.Subscribe().Collect(), s.Subscribe().Take(2).Collect()
+
+	s.Emit(1)
+	val, err := next.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, val)
+
+	s.Emit(2)
+	s.Emit(3)
+	s.Complete()
+	s.Emit(4)
+
+	res, err := first.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, len(res))
+	assertEqual(t, 3, res[2])
+
+	res, err = second.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 2, len(res))
+
+	_, err = s.Next().Await()
+	assertEqual(t, ErrSubjectCompleted, err)
+}
+
+func TestSubject_Fail(t *testing.T) {
+	s := NewSubject[int]()
+	next := s.Next()
+	stream := s.Subscribe().Collect()
+	s.Emit(1)
+	s.Fail(promiseError)
+
+	_, err := stream.Await()
+	assertEqual(t, promiseError, err)
+	val, err := next.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 1, val)
+
+	_, err = s.Subscribe().Collect().Await()
+	assertEqual(t, promiseError, err)
+}
+
+func TestSubject_UnsubscribesOnStop(t *testing.T) {
+	s := NewSubject[int]()
+	taken := s.Subscribe().Take(1).Collect()
+	s.Emit(1)
+	_, err := taken.Await()
+	assertNotErr(t, err)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mutex.Lock()
+		n := len(s.subs)
+		s.mutex.Unlock()
+		if n == 0 {
+			break
+		}
+		assert(t, time.Now().Before(deadline), "subscription should be dropped")
+		time.Sleep(time.Millisecond)
+	}
+}
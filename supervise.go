@@ -0,0 +1,77 @@
+package gopromise
+
+import (
+	"time"
+)
+
+// RestartMode selects which failures a supervisor restarts after.
+type RestartMode int
+
+const (
+	// RestartAlways restarts after any rejection, panics included.
+	RestartAlways RestartMode = iota
+	// RestartOnPanic restarts only after a panic; other rejections are final.
+	RestartOnPanic
+)
+
+// RestartPolicy tells Supervise when and how to restart failed work.
+type RestartPolicy struct {
+	Mode RestartMode
+	// MaxRestarts caps the number of restarts; zero means no cap.
+	MaxRestarts int
+	// Backoff, when not nil, says how long to wait before each restart.
+	Backoff Backoff
+}
+
+// Supervise returns a Promise that runs the work produced by factory and
+// restarts it according to policy when it rejects or panics, in the spirit of
+// an Erlang supervisor. It fulfills with the first run that fulfills and
+// rejects with the reason of the last run once policy gives up. Cancelling
+// the returned promise stops further restarts.
+func Supervise[T any](policy RestartPolicy, factory func() *Promise[T]) *Promise[T] {
+	if factory == nil {
+		panic("factory cannot be nil")
+	}
+	p := newPromise[T]()
+	p.start(func(resolve func(T), reject func(error)) {
+		for restarts := 0; ; restarts++ {
+			val, err, panicked := superviseOnce(factory)
+			if err == nil {
+				resolve(val)
+				return
+			}
+			if (policy.Mode == RestartOnPanic && !panicked) ||
+				(policy.MaxRestarts > 0 && restarts >= policy.MaxRestarts) {
+				reject(err)
+				return
+			}
+			if policy.Backoff != nil {
+				timer := time.NewTimer(policy.Backoff(restarts))
+				select {
+				case <-p.done:
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			} else if p.isSettled() {
+				return
+			}
+		}
+	}, nil)
+	return p
+}
+
+// superviseOnce runs one attempt and reports whether it panicked, either in
+// factory itself or in the executor of the promise it returned.
+func superviseOnce[T any](factory func() *Promise[T]) (val T, err error, panicked bool) {
+	returned := false
+	defer func() {
+		if !returned {
+			err, panicked = panicError(recover(), nil), true
+		}
+	}()
+	attempt := orReject(factory())
+	returned = true
+	val, err = attempt.await()
+	return val, err, err != nil && attempt.panicked
+}
@@ -0,0 +1,65 @@
+package gopromise
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSupervise_RestartsUntilSuccess(t *testing.T) {
+	var runs int32
+	p := Supervise(RestartPolicy{Mode: RestartAlways}, func() *Promise[int] {
+		return New(func(resolve func(int), reject func(error)) {
+			switch atomic.AddInt32(&runs, 1) {
+			case 1:
+				panic("boom")
+			case 2:
+				reject(promiseError)
+			default:
+				resolve(3)
+			}
+		})
+	})
+
+	res, err := p.Await()
+	assertNotErr(t, err)
+	assertEqual(t, 3, res)
+}
+
+func TestSupervise_OnPanic(t *testing.T) {
+	var runs int32
+	p := Supervise(RestartPolicy{Mode: RestartOnPanic}, func() *Promise[int] {
+		return New(func(resolve func(int), reject func(error)) {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				panic("boom")
+			}
+			reject(promiseError)
+		})
+	})
+
+	_, err := p.Await()
+	assertEqual(t, promiseError, err)
+	assertEqual(t, int32(2), atomic.LoadInt32(&runs))
+}
+
+func TestSupervise_MaxRestarts(t *testing.T) {
+	var runs int32
+	policy := RestartPolicy{
+		MaxRestarts: 2,
+		Backoff:     func(int) time.Duration { return time.Millisecond },
+	}
+	p := Supervise(policy, func() *Promise[int] {
+		atomic.AddInt32(&runs, 1)
+		return Reject[int](promiseError)
+	})
+
+	_, err := p.Await()
+	assertEqual(t, promiseError, err)
+	assertEqual(t, int32(3), atomic.LoadInt32(&runs))
+}
+
+func TestSupervise_NilPromise(t *testing.T) {
+	p := Supervise(RestartPolicy{MaxRestarts: 1}, func() *Promise[int] { return nil })
+	_, err := p.Await()
+	assertEqual(t, ErrNilPromise, err)
+}
@@ -0,0 +1,136 @@
+package gopromise
+
+import "sync"
+
+// Watch holds a value that is refreshed over time, such as configuration,
+// and lets consumers react to each change.
+//
+// Changes are delivered to OnChange callbacks one at a time, in the order
+// they were made, and never concurrently with each other. A callback may
+// itself update the Watch; the new change is delivered after the current one.
+type Watch[T any] struct {
+	mutex       sync.Mutex
+	value       T
+	version     uint64
+	subs        map[uint64]watchSub[T]
+	nextSub     uint64
+	waiters     []*Promise[T]
+	queue       []watchChange[T]
+	dispatching bool
+}
+
+type watchSub[T any] struct {
+	cb    func(T)
+	since uint64
+}
+
+type watchChange[T any] struct {
+	val     T
+	version uint64
+}
+
+// NewWatch returns a Watch holding initial.
+func NewWatch[T any](initial T) *Watch[T] {
+	return &Watch[T]{value: initial, subs: make(map[uint64]watchSub[T])}
+}
+
+// Current returns the value held by w.
+func (w *Watch[T]) Current() T {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.value
+}
+
+// Version returns the number of changes made to w so far.
+func (w *Watch[T]) Version() uint64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.version
+}
+
+// Set replaces the value held by w.
+func (w *Watch[T]) Set(val T) {
+	w.UpdateIfChanged(func(T) (T, bool) { return val, true })
+}
+
+// UpdateIfChanged atomically computes the next value from the current one.
+// When fn reports no change, w is left untouched and no callback runs.
+// UpdateIfChanged reports whether the value was changed.
+func (w *Watch[T]) UpdateIfChanged(fn func(cur T) (next T, changed bool)) bool {
+	w.mutex.Lock()
+	next, changed := fn(w.value)
+	if !changed {
+		w.mutex.Unlock()
+		return false
+	}
+	w.value = next
+	w.version++
+	waiters := w.waiters
+	w.waiters = nil
+	w.queue = append(w.queue, watchChange[T]{next, w.version})
+	dispatch := !w.dispatching
+	w.dispatching = true
+	w.mutex.Unlock()
+
+	for _, p := range waiters {
+		p.resolve(next)
+	}
+	if dispatch {
+		w.dispatch()
+	}
+	return true
+}
+
+// dispatch delivers queued changes to the subscribers until the queue is
+// empty. Only one goroutine dispatches at a time.
+func (w *Watch[T]) dispatch() {
+	for {
+		w.mutex.Lock()
+		if len(w.queue) == 0 {
+			w.dispatching = false
+			w.mutex.Unlock()
+			return
+		}
+		change := w.queue[0]
+		w.queue = w.queue[1:]
+		subs := make([]func(T), 0, len(w.subs))
+		for _, sub := range w.subs {
+			// Subscribers registered after this change already got its
+			// value back from OnChange.
+			if sub.since < change.version {
+				subs = append(subs, sub.cb)
+			}
+		}
+		w.mutex.Unlock()
+
+		for _, cb := range subs {
+			cb(change.val)
+		}
+	}
+}
+
+// OnChange registers cb to be called with every future value of w. It
+// returns the value current at registration time, so no change can slip in
+// between reading the value and subscribing, and a function that removes the
+// registration.
+func (w *Watch[T]) OnChange(cb func(val T)) (current T, cancel func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	id := w.nextSub
+	w.nextSub++
+	w.subs[id] = watchSub[T]{cb: cb, since: w.version}
+	return w.value, func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		delete(w.subs, id)
+	}
+}
+
+// Next returns a promise fulfilled with the next value of w.
+func (w *Watch[T]) Next() *Promise[T] {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	p := newPromise[T]()
+	w.waiters = append(w.waiters, p)
+	return p
+}
@@ -0,0 +1,61 @@
+package gopromise
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestWatch_OnChange(t *testing.T) {
+	w := NewWatch(1)
+
+	var seen []int
+	cur, cancel := w.OnChange(func(v int) { seen = append(seen, v) })
+	assertEqual(t, 1, cur)
+
+	w.Set(2)
+	changed := w.UpdateIfChanged(func(cur int) (int, bool) { return cur, false })
+	assert(t, !changed, "unchanged update should report false")
+	w.UpdateIfChanged(func(cur int) (int, bool) { return cur + 1, true })
+	cancel()
+	w.Set(10)
+
+	assertEqual(t, 2, len(seen))
+	assertEqual(t, 2, seen[0])
+	assertEqual(t, 3, seen[1])
+	assertEqual(t, 10, w.Current())
+	assertEqual(t, uint64(3), w.Version())
+}
+
+func TestWatch_OrderedDelivery(t *testing.T) {
+	w := NewWatch(0)
+
+	var mutex sync.Mutex
+	last := 0
+	w.OnChange(func(v int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert(t, v > last, "changes delivered out of order")
+		last = v
+	})
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < 50; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.UpdateIfChanged(func(cur int) (int, bool) { return cur + 1, true })
+		}()
+	}
+	wg.Wait()
+	assertEqual(t, 50, w.Current())
+}
+
+func TestWatch_Next(t *testing.T) {
+	w := NewWatch("a")
+	next := w.Next()
+	w.Set("b")
+
+	res, err := next.Await()
+	assertNotErr(t, err)
+	assertEqual(t, "b", res)
+}